@@ -15,8 +15,14 @@ import (
 	"github.com/weatherpro/backend/internal/config"
 	"github.com/weatherpro/backend/internal/core/services"
 	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/clients/appstore"
+	"github.com/weatherpro/backend/internal/platform/clients/googleplay"
 	"github.com/weatherpro/backend/internal/platform/clients/openweathermap"
+	"github.com/weatherpro/backend/internal/platform/clients/rainviewer"
+	"github.com/weatherpro/backend/internal/platform/clients/stripe"
 	"github.com/weatherpro/backend/internal/platform/database"
+	"github.com/weatherpro/backend/internal/platform/push"
+	"github.com/weatherpro/backend/internal/platform/safehttp"
 )
 
 func main() {
@@ -41,30 +47,137 @@ func main() {
 	}
 	owmClient := openweathermap.NewClient(httpClient, cfg.OpenWeatherMapAPIKey)
 
+	// Inicializa o cliente de radar da RainViewer
+	radarClient := rainviewer.NewClient(httpClient, cfg.RainViewerAPIURL)
+
+	// Inicializa os validadores de compras das lojas
+	googlePlayClient, err := googleplay.NewClient(httpClient, cfg.GooglePlayAPIURL, cfg.GooglePlayPackageName, cfg.GoogleServiceAccountFile)
+	if err != nil {
+		log.Fatalf("Could not create Google Play client: %v", err)
+	}
+	pushVerifier := googleplay.NewPushVerifier(httpClient, cfg.GoogleOAuthCertsURL, cfg.GooglePubSubAudience, cfg.GooglePubSubServiceAccount)
+	appStoreVerifier, err := appstore.NewVerifier(cfg.AppStoreRootCAPath, cfg.AppStoreBundleID, cfg.AppStoreEnvironment)
+	if err != nil {
+		log.Fatalf("Could not create App Store verifier: %v", err)
+	}
+
+	// Inicializa o cliente de cobrança web da Stripe
+	stripeClient := stripe.NewClient(httpClient, cfg.StripeAPIURL, cfg.StripeSecretKey, cfg.StripeWebhookSecret)
+
 	// Inicializa os repositórios
 	userRepo := database.NewUserRepository(db)
 	favoriteCityRepo := database.NewFavoriteCityRepository(db)
+	favoriteGroupRepo := database.NewFavoriteGroupRepository(db)
+	syncRepo := database.NewSyncRepository(db)
 	notificationSettingsRepo := database.NewNotificationSettingsRepository(db)
 	subscriptionRepo := database.NewSubscriptionRepository(db)
+	notificationRepo := database.NewNotificationRepository(db)
+	alertLocationRepo := database.NewAlertLocationRepository(db)
+	webhookEndpointRepo := database.NewWebhookEndpointRepository(db)
+	webhookDeliveryRepo := database.NewWebhookDeliveryRepository(db)
+	subscriptionEventRepo := database.NewSubscriptionEventRepository(db)
+	promoCodeRepo := database.NewPromoCodeRepository(db)
+	referralRepo := database.NewReferralRepository(db)
+	locationInterestRepo := database.NewLocationInterestRepository(db)
 
 	// Inicializa os serviços
-	weatherCache := cache.NewWeatherCache(valkeyClient)
-	weatherService := services.NewWeatherService(owmClient, weatherCache)
-	userService := services.NewUserService(userRepo)
-	favoriteCityService := services.NewFavoriteCityService(favoriteCityRepo)
+	quantizer, err := cache.NewQuantizer(cfg.CacheQuantization, cfg.CacheGeohashPrecision, cfg.CacheGridDegrees)
+	if err != nil {
+		log.Fatalf("Invalid cache quantization: %v", err)
+	}
+	codec, err := cache.NewCodec(cfg.CacheCodec)
+	if err != nil {
+		log.Fatalf("Invalid cache codec: %v", err)
+	}
+	localCacheTTL := time.Duration(cfg.CacheLocalTTLSeconds) * time.Second
+	weatherCache := cache.NewTieredCache(cache.NewWeatherCache(valkeyClient, quantizer, codec), valkeyClient, cfg.CacheLocalSize, localCacheTTL)
+	fetchLock := cache.NewFetchLock(valkeyClient)
+	upstreamQuotaService := services.NewUpstreamQuotaService(cache.NewSlidingWindowCounter(valkeyClient), "openweathermap", services.UpstreamLimits{
+		PerMinute: cfg.OpenWeatherMapCallsPerMinute,
+		PerDay:    cfg.OpenWeatherMapCallsPerDay,
+	})
+	weatherService := services.NewWeatherService(owmClient, weatherCache, fetchLock, services.WeatherCacheTTL{
+		Soft: time.Duration(cfg.CacheSoftTTLSeconds) * time.Second,
+		Hard: time.Duration(cfg.CacheHardTTLSeconds) * time.Second,
+	}, upstreamQuotaService)
+	cacheAdminService := services.NewCacheAdminService(weatherCache, upstreamQuotaService)
+	cacheWarmingService := services.NewCacheWarmingService(locationInterestRepo, weatherService, fetchLock, cfg.CacheWarmBudgetPerMinute)
+	entitlementService := services.NewEntitlementService(subscriptionRepo, cache.NewUsageCounter(valkeyClient))
 	notificationSettingsService := services.NewNotificationSettingsService(notificationSettingsRepo)
-	subscriptionService := services.NewSubscriptionService(subscriptionRepo)
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, subscriptionEventRepo, googlePlayClient, pushVerifier, appStoreVerifier, stripeClient, promoCodeRepo, cfg.StoreProductPlans, services.SubscriptionLifecycle{
+		TrialPlan:   cfg.SubscriptionTrialPlan,
+		TrialPeriod: time.Duration(cfg.SubscriptionTrialDays) * 24 * time.Hour,
+		GracePeriod: time.Duration(cfg.SubscriptionGracePeriodDays) * 24 * time.Hour,
+	})
+	userService := services.NewUserService(userRepo, referralRepo, subscriptionService, cfg.ReferralRewardDays, cfg.ReferralMaxRewards)
+	promoCodeService := services.NewPromoCodeService(promoCodeRepo, subscriptionService)
+	webhookService := services.NewWebhookService(webhookEndpointRepo, webhookDeliveryRepo, safehttp.NewClient(10*time.Second))
+	notificationService := services.NewNotificationService(notificationRepo, notificationSettingsRepo, push.NewLogSender(), webhookService, fetchLock)
+	alertLocationService := services.NewAlertLocationService(alertLocationRepo, favoriteCityRepo, entitlementService)
+	nowcastService := services.NewNowcastService(radarClient)
+	alertService := services.NewAlertService(alertLocationRepo, nowcastService, notificationService, cache.NewAlertDedup(valkeyClient))
+	favoriteCityService := services.NewFavoriteCityService(favoriteCityRepo, favoriteGroupRepo, entitlementService, weatherService, alertService)
+	favoriteGroupService := services.NewFavoriteGroupService(favoriteGroupRepo)
+	syncService := services.NewSyncService(syncRepo, favoriteCityRepo, notificationSettingsRepo, favoriteCityService)
+	favoriteTransferService := services.NewFavoriteTransferService(favoriteCityService, owmClient, upstreamQuotaService)
+	digestService := services.NewDigestService(notificationSettingsRepo, favoriteCityRepo, weatherService, notificationService, fetchLock)
 
 	// Inicializa os handlers
-	weatherHandler := handlers.NewWeatherHandler(weatherService)
+	weatherHandler := handlers.NewWeatherHandler(weatherService, entitlementService, favoriteCityService)
 	userHandler := handlers.NewUserHandler(userService)
 	favoriteCityHandler := handlers.NewFavoriteCityHandler(favoriteCityService)
+	favoriteGroupHandler := handlers.NewFavoriteGroupHandler(favoriteGroupService)
+	syncHandler := handlers.NewSyncHandler(syncService)
+	favoriteTransferHandler := handlers.NewFavoriteTransferHandler(favoriteTransferService)
 	notificationSettingsHandler := handlers.NewNotificationSettingsHandler(notificationSettingsService)
 	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService)
 	mapsHandler := handlers.NewMapsHandler()
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	alertLocationHandler := handlers.NewAlertLocationHandler(alertLocationService)
+	nowcastHandler := handlers.NewNowcastHandler(nowcastService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	entitlementHandler := handlers.NewEntitlementHandler(entitlementService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	cacheAdminHandler := handlers.NewCacheAdminHandler(cacheAdminService)
 
 	// Inicializa o roteador
-	router := api.NewRouter(weatherHandler, userHandler, favoriteCityHandler, notificationSettingsHandler, subscriptionHandler, mapsHandler)
+	router := api.NewRouter(
+		weatherHandler,
+		userHandler,
+		favoriteCityHandler,
+		favoriteGroupHandler,
+		syncHandler,
+		favoriteTransferHandler,
+		notificationSettingsHandler,
+		subscriptionHandler,
+		mapsHandler,
+		notificationHandler,
+		alertLocationHandler,
+		nowcastHandler,
+		webhookHandler,
+		entitlementHandler,
+		promoCodeHandler,
+		cacheAdminHandler,
+		entitlementService,
+		cfg.AdminAPIToken,
+	)
+
+	// Inicia os jobs em segundo plano
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go weatherCache.RunInvalidationListener(jobsCtx)
+	go weatherCache.RunStatsFlusher(jobsCtx, 10*time.Second)
+	go upstreamQuotaService.RunMonitor(jobsCtx, 5*time.Second)
+	go cacheWarmingService.RunWarmer(jobsCtx, time.Minute)
+
+	retention := time.Duration(cfg.NotificationRetentionDays) * 24 * time.Hour
+	go notificationService.RunRetentionCleanup(jobsCtx, retention, time.Hour)
+	go notificationService.RunDeliveryDispatcher(jobsCtx, time.Minute)
+	go webhookService.RunDeliveryDispatcher(jobsCtx, 5*time.Second)
+	go digestService.RunScheduler(jobsCtx, time.Minute)
+	go alertService.RunRainAlerts(jobsCtx, 5*time.Minute)
+	go subscriptionService.RunLifecycle(jobsCtx, 10*time.Minute)
 
 	// Cria o servidor HTTP
 	server := &http.Server{
@@ -90,6 +203,7 @@ func main() {
 	defer cancel()
 
 	log.Println("Shutting down server...")
+	stopJobs()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown failed: %v", err)
 	}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// AlertLocationHandler é um handler para locais de alerta.
+type AlertLocationHandler struct {
+	service *services.AlertLocationService
+}
+
+// NewAlertLocationHandler cria um novo AlertLocationHandler.
+func NewAlertLocationHandler(service *services.AlertLocationService) *AlertLocationHandler {
+	return &AlertLocationHandler{
+		service: service,
+	}
+}
+
+// GetAlertLocations lista os locais de alerta de um usuário.
+func (h *AlertLocationHandler) GetAlertLocations(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	locations, err := h.service.GetAlertLocationsByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(locations); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateAlertLocation cria um local de alerta.
+func (h *AlertLocationHandler) CreateAlertLocation(w http.ResponseWriter, r *http.Request) {
+	var loc domain.AlertLocation
+	if err := json.NewDecoder(r.Body).Decode(&loc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	loc.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.CreateAlertLocation(r.Context(), &loc); err != nil {
+		writeAlertLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(loc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// UpdateAlertLocation atualiza um local de alerta.
+func (h *AlertLocationHandler) UpdateAlertLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid alert location ID", http.StatusBadRequest)
+		return
+	}
+
+	var loc domain.AlertLocation
+	if err := json.NewDecoder(r.Body).Decode(&loc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	loc.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	loc.ID = id
+
+	if err := h.service.UpdateAlertLocation(r.Context(), &loc); err != nil {
+		writeAlertLocationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetCurrentLocation atualiza a localização atual do aparelho usada para alertas.
+func (h *AlertLocationHandler) SetCurrentLocation(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Name string  `json:"name"`
+		Lat  float64 `json:"lat"`
+		Lon  float64 `json:"lon"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	loc, err := h.service.SetCurrentLocation(r.Context(), userID, req.Name, req.Lat, req.Lon)
+	if err != nil {
+		writeAlertLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(loc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteAlertLocation remove um local de alerta.
+func (h *AlertLocationHandler) DeleteAlertLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid alert location ID", http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.DeleteAlertLocation(r.Context(), userID, id); err != nil {
+		writeAlertLocationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAlertLocationError(w http.ResponseWriter, err error) {
+	if WritePlanLimitError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidAlertLocation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrAlertLocationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrAlertLocationExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
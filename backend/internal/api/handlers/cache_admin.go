@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// CacheAdminHandler é um handler para a administração do cache de clima.
+type CacheAdminHandler struct {
+	service *services.CacheAdminService
+}
+
+// NewCacheAdminHandler cria um novo CacheAdminHandler.
+func NewCacheAdminHandler(service *services.CacheAdminService) *CacheAdminHandler {
+	return &CacheAdminHandler{
+		service: service,
+	}
+}
+
+// GetEntry mostra a entrada de um local (lat e lon) ou de uma chave de célula (key).
+func (h *CacheAdminHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var entry *domain.CacheEntry
+	var err error
+	if key := query.Get("key"); key != "" {
+		entry, err = h.service.InspectKey(r.Context(), key)
+	} else {
+		lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+		lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+		if errLat != nil || errLon != nil || (domain.Coordinates{Lat: lat, Lon: lon}).Validate() != nil {
+			http.Error(w, "key or valid lat and lon query parameters are required", http.StatusBadRequest)
+			return
+		}
+		entry, err = h.service.InspectLocation(r.Context(), lat, lon)
+	}
+	if err != nil {
+		writeCacheAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Purge remove entradas por chave, prefixo ou região.
+func (h *CacheAdminHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	var purge domain.CachePurge
+	if err := json.NewDecoder(r.Body).Decode(&purge); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	purged, err := h.service.Purge(r.Context(), &purge)
+	if err != nil {
+		writeCacheAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"purged": purged}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetStats mostra as métricas do cache.
+func (h *CacheAdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetStats(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetUpstreamQuota mostra o consumo da cota da API externa e o nível de economia.
+func (h *CacheAdminHandler) GetUpstreamQuota(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetUpstreamQuota(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeCacheAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCacheEntryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidCachePurge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// EntitlementHandler é um handler para os direitos do plano do usuário.
+type EntitlementHandler struct {
+	service *services.EntitlementService
+}
+
+// NewEntitlementHandler cria um novo EntitlementHandler.
+func NewEntitlementHandler(service *services.EntitlementService) *EntitlementHandler {
+	return &EntitlementHandler{
+		service: service,
+	}
+}
+
+// GetEntitlements obtém o plano vigente do usuário e seus direitos.
+func (h *EntitlementHandler) GetEntitlements(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	plan, err := h.service.GetPlan(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WritePlanLimitError responde com 402 (limite numérico atingido) ou 403
+// (recurso fora do plano) e uma sugestão de upgrade, se err for um erro de
+// plano. Retorna false, sem escrever nada, para outros erros.
+func WritePlanLimitError(w http.ResponseWriter, err error) bool {
+	var limitErr *domain.PlanLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	type response struct {
+		Error string `json:"error"`
+		*domain.PlanLimitError
+	}
+
+	status := http.StatusPaymentRequired
+	if domain.IsBooleanFeature(limitErr.Feature) {
+		status = http.StatusForbidden
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response{Error: limitErr.Error(), PlanLimitError: limitErr})
+	return true
+}
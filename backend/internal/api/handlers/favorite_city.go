@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -33,7 +34,7 @@ func (h *FavoriteCityHandler) CreateFavoriteCity(w http.ResponseWriter, r *http.
 	city.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
 
 	if err := h.service.CreateFavoriteCity(r.Context(), &city); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFavoriteCityError(w, err)
 		return
 	}
 
@@ -44,14 +45,36 @@ func (h *FavoriteCityHandler) CreateFavoriteCity(w http.ResponseWriter, r *http.
 	}
 }
 
-// GetFavoriteCities busca todas as cidades favoritas de um usuário.
+// GetFavoriteCities busca as cidades favoritas de um usuário, opcionalmente só
+// as de um grupo (?group=) ou com uma tag (?tag=). Com ?include=current, cada
+// cidade traz também as condições atuais.
 func (h *FavoriteCityHandler) GetFavoriteCities(w http.ResponseWriter, r *http.Request) {
 	// TODO: Obter o ID do usuário a partir do contexto
 	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
 
-	cities, err := h.service.GetFavoriteCitiesByUserID(r.Context(), userID)
+	filter := domain.FavoriteCityFilter{Tag: r.URL.Query().Get("tag")}
+	if groupStr := r.URL.Query().Get("group"); groupStr != "" {
+		groupID, err := uuid.Parse(groupStr)
+		if err != nil {
+			http.Error(w, "invalid group parameter", http.StatusBadRequest)
+			return
+		}
+		filter.GroupID = &groupID
+	}
+
+	var cities []*domain.FavoriteCity
+	var err error
+	switch include := r.URL.Query().Get("include"); include {
+	case "":
+		cities, err = h.service.GetFavoriteCitiesByUserID(r.Context(), userID, filter)
+	case "current":
+		cities, err = h.service.GetFavoriteCitiesWithCurrent(r.Context(), userID, filter)
+	default:
+		http.Error(w, "invalid include parameter", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFavoriteCityError(w, err)
 		return
 	}
 
@@ -61,10 +84,63 @@ func (h *FavoriteCityHandler) GetFavoriteCities(w http.ResponseWriter, r *http.R
 	}
 }
 
+// UpdateFavoriteCity altera nome, apelido, coordenadas ou país de uma cidade
+// favorita. Campos ausentes no corpo ficam como estão.
+func (h *FavoriteCityHandler) UpdateFavoriteCity(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid favorite city ID", http.StatusBadRequest)
+		return
+	}
+
+	var patch domain.FavoriteCityPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	city, err := h.service.UpdateFavoriteCity(r.Context(), userID, id, &patch)
+	if err != nil {
+		writeFavoriteCityError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(city); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ReorderFavoriteCities define a ordem das cidades favoritas a partir da lista
+// completa de IDs.
+func (h *FavoriteCityHandler) ReorderFavoriteCities(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.ReorderFavoriteCities(r.Context(), userID, req.IDs); err != nil {
+		writeFavoriteCityError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // DeleteFavoriteCity remove uma cidade favorita.
 func (h *FavoriteCityHandler) DeleteFavoriteCity(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/favorites/"):]
-	id, err := uuid.Parse(idStr)
+	id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
 		http.Error(w, "invalid favorite city ID", http.StatusBadRequest)
 		return
@@ -77,3 +153,20 @@ func (h *FavoriteCityHandler) DeleteFavoriteCity(w http.ResponseWriter, r *http.
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func writeFavoriteCityError(w http.ResponseWriter, err error) {
+	if WritePlanLimitError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidFavoriteCity), errors.Is(err, domain.ErrInvalidFavoriteOrder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrFavoriteCityNotFound), errors.Is(err, domain.ErrFavoriteGroupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrFavoriteCityExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// FavoriteGroupHandler é um handler para grupos de cidades favoritas.
+type FavoriteGroupHandler struct {
+	service *services.FavoriteGroupService
+}
+
+// NewFavoriteGroupHandler cria um novo FavoriteGroupHandler.
+func NewFavoriteGroupHandler(service *services.FavoriteGroupService) *FavoriteGroupHandler {
+	return &FavoriteGroupHandler{
+		service: service,
+	}
+}
+
+// GetFavoriteGroups lista os grupos do usuário.
+func (h *FavoriteGroupHandler) GetFavoriteGroups(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	groups, err := h.service.GetFavoriteGroupsByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(groups); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateFavoriteGroup cria um grupo.
+func (h *FavoriteGroupHandler) CreateFavoriteGroup(w http.ResponseWriter, r *http.Request) {
+	var group domain.FavoriteGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	group.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	group.FavoritesCount = 0
+
+	if err := h.service.CreateFavoriteGroup(r.Context(), &group); err != nil {
+		writeFavoriteGroupError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(group); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RenameFavoriteGroup altera o nome de um grupo.
+func (h *FavoriteGroupHandler) RenameFavoriteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid favorite group ID", http.StatusBadRequest)
+		return
+	}
+
+	type request struct {
+		Name string `json:"name"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	group, err := h.service.RenameFavoriteGroup(r.Context(), userID, id, req.Name)
+	if err != nil {
+		writeFavoriteGroupError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(group); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteFavoriteGroup remove um grupo; as cidades dele continuam favoritas.
+func (h *FavoriteGroupHandler) DeleteFavoriteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid favorite group ID", http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.DeleteFavoriteGroup(r.Context(), userID, id); err != nil {
+		writeFavoriteGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeFavoriteGroupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidFavoriteGroup):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrFavoriteGroupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrFavoriteGroupExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
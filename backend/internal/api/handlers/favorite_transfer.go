@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+	"github.com/weatherpro/backend/internal/platform/geoformat"
+)
+
+// maxFavoriteImportBytes limita o tamanho do arquivo importado.
+const maxFavoriteImportBytes = 5 << 20
+
+// FavoriteTransferHandler é um handler para exportar e importar cidades
+// favoritas.
+type FavoriteTransferHandler struct {
+	service *services.FavoriteTransferService
+}
+
+// NewFavoriteTransferHandler cria um novo FavoriteTransferHandler.
+func NewFavoriteTransferHandler(service *services.FavoriteTransferService) *FavoriteTransferHandler {
+	return &FavoriteTransferHandler{
+		service: service,
+	}
+}
+
+// ExportFavoriteCities baixa as cidades favoritas no formato de ?format=
+// (geojson, kml ou csv), com os mesmos filtros ?group= e ?tag= da listagem.
+func (h *FavoriteTransferHandler) ExportFavoriteCities(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+
+	filter := domain.FavoriteCityFilter{Tag: r.URL.Query().Get("tag")}
+	if groupStr := r.URL.Query().Get("group"); groupStr != "" {
+		groupID, err := uuid.Parse(groupStr)
+		if err != nil {
+			http.Error(w, "invalid group parameter", http.StatusBadRequest)
+			return
+		}
+		filter.GroupID = &groupID
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	// O arquivo é montado antes da resposta para que uma falha ainda possa
+	// ser informada com o status correto
+	var buf bytes.Buffer
+	if err := h.service.ExportFavoriteCities(r.Context(), userID, format, filter, &buf); err != nil {
+		writeFavoriteTransferError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", geoformat.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="favorites.%s"`, format))
+	if _, err := buf.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ImportFavoriteCities cria favoritas a partir do arquivo enviado no corpo, no
+// formato de ?format=. Com ?group=, as cidades entram nesse grupo. A resposta
+// traz o resultado de cada local do arquivo.
+func (h *FavoriteTransferHandler) ImportFavoriteCities(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if !domain.ValidFavoriteFormat(format) {
+		http.Error(w, "invalid format parameter", http.StatusBadRequest)
+		return
+	}
+
+	var groupID *uuid.UUID
+	if groupStr := r.URL.Query().Get("group"); groupStr != "" {
+		id, err := uuid.Parse(groupStr)
+		if err != nil {
+			http.Error(w, "invalid group parameter", http.StatusBadRequest)
+			return
+		}
+		groupID = &id
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	body := http.MaxBytesReader(w, r.Body, maxFavoriteImportBytes)
+	report, err := h.service.ImportFavoriteCities(r.Context(), userID, format, groupID, body)
+	if err != nil {
+		writeFavoriteTransferError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeFavoriteTransferError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, domain.ErrUnsupportedFavoriteFormat), errors.Is(err, domain.ErrInvalidFavoriteImport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeFavoriteCityError(w, err)
+	}
+}
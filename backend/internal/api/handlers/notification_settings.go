@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -49,7 +50,12 @@ func (h *NotificationSettingsHandler) UpdateNotificationSettings(w http.Response
 	// TODO: Obter o ID do usuário a partir do contexto
 	settings.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
 
-	if err := h.service.UpdateNotificationSettings(r.Context(), &settings); err != nil {
+	err := h.service.UpdateNotificationSettings(r.Context(), &settings)
+	if errors.Is(err, domain.ErrInvalidNotificationSettings) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
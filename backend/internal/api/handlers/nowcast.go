@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// NowcastHandler é um handler para a previsão de precipitação de curtíssimo prazo.
+type NowcastHandler struct {
+	nowcastService *services.NowcastService
+}
+
+// NewNowcastHandler cria um novo NowcastHandler.
+func NewNowcastHandler(nowcastService *services.NowcastService) *NowcastHandler {
+	return &NowcastHandler{
+		nowcastService: nowcastService,
+	}
+}
+
+// GetNowcast obtém a previsão de precipitação minuto a minuto da próxima hora.
+func (h *NowcastHandler) GetNowcast(w http.ResponseWriter, r *http.Request) {
+	latStr := r.URL.Query().Get("lat")
+	lonStr := r.URL.Query().Get("lon")
+
+	if latStr == "" || lonStr == "" {
+		http.Error(w, "lat and lon query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil || !(lat >= -85 && lat <= 85) {
+		http.Error(w, "invalid lat parameter", http.StatusBadRequest)
+		return
+	}
+
+	lon, err := strconv.ParseFloat(lonStr, 64)
+	if err != nil || !(lon >= -180 && lon <= 180) {
+		http.Error(w, "invalid lon parameter", http.StatusBadRequest)
+		return
+	}
+
+	nowcast, err := h.nowcastService.GetNowcast(r.Context(), lat, lon)
+	if errors.Is(err, services.ErrNoRadarData) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(nowcast); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
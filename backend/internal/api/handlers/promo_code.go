@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// PromoCodeHandler é um handler para códigos promocionais.
+type PromoCodeHandler struct {
+	service *services.PromoCodeService
+}
+
+// NewPromoCodeHandler cria um novo PromoCodeHandler.
+func NewPromoCodeHandler(service *services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{
+		service: service,
+	}
+}
+
+// ListPromoCodes lista os códigos promocionais cadastrados.
+func (h *PromoCodeHandler) ListPromoCodes(w http.ResponseWriter, r *http.Request) {
+	codes, err := h.service.ListPromoCodes(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(codes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreatePromoCode cadastra um código promocional.
+func (h *PromoCodeHandler) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var promo domain.PromoCode
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreatePromoCode(r.Context(), &promo); err != nil {
+		if !WritePromoCodeError(w, err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(promo); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RedeemPromoCode resgata um código de duração e retorna a assinatura estendida.
+func (h *PromoCodeHandler) RedeemPromoCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	sub, err := h.service.RedeemPromoCode(r.Context(), userID, req.Code)
+	if err != nil {
+		if !WritePromoCodeError(w, err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WritePromoCodeError responde aos erros de código promocional e informa se o
+// erro era um deles.
+func WritePromoCodeError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrPromoCodeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidPromoCode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrPromoCodeNotApplicable):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrPromoCodeExpired),
+		errors.Is(err, domain.ErrPromoCodeExhausted),
+		errors.Is(err, domain.ErrPromoCodeAlreadyRedeemed),
+		errors.Is(err, domain.ErrPromoCodeExists),
+		errors.Is(err, domain.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		return false
+	}
+	return true
+}
@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/core/services"
+	"github.com/weatherpro/backend/internal/platform/clients/googleplay"
 )
 
 // SubscriptionHandler é um handler para assinaturas.
@@ -38,21 +43,141 @@ func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Req
 	}
 }
 
-// UpdateSubscription atualiza a assinatura de um usuário.
-func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
-	var sub domain.Subscription
-	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+// VerifySubscription valida uma compra feita na Google Play ou na App Store e
+// retorna a assinatura derivada pelo servidor.
+func (h *SubscriptionHandler) VerifySubscription(w http.ResponseWriter, r *http.Request) {
+	var req domain.PurchaseVerification
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// TODO: Obter o ID do usuário a partir do contexto
-	sub.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	sub, err := h.service.VerifyPurchase(r.Context(), userID, &req)
+	switch {
+	case errors.Is(err, domain.ErrInvalidPurchase):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, domain.ErrPurchaseInUse), errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrTrialAlreadyUsed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
 
-	if err := h.service.UpdateSubscription(r.Context(), &sub); err != nil {
+// StartTrial concede o período de teste gratuito, uma única vez por usuário.
+func (h *SubscriptionHandler) StartTrial(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	sub, err := h.service.StartTrial(r.Context(), userID)
+	switch {
+	case errors.Is(err, domain.ErrTrialAlreadyUsed), errors.Is(err, domain.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateCheckoutSession cria uma sessão de checkout da Stripe para assinar pela web.
+func (h *SubscriptionHandler) CreateCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	var req domain.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	session, err := h.service.CreateCheckoutSession(r.Context(), userID, &req)
+	if err != nil && WritePromoCodeError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrInvalidPurchase):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(session); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// StripeWebhook recebe os eventos de cobrança da Stripe. A assinatura é
+// calculada sobre o corpo bruto, que por isso é lido antes de qualquer decodificação.
+func (h *SubscriptionHandler) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.HandleStripeEvent(r.Context(), payload, r.Header.Get("Stripe-Signature"))
+	writeStoreNotificationResult(w, err)
+}
+
+// GooglePlayNotification recebe as notificações em tempo real da Google Play
+// entregues pelo push do Pub/Sub.
+func (h *SubscriptionHandler) GooglePlayNotification(w http.ResponseWriter, r *http.Request) {
+	var req googleplay.PushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	err := h.service.HandleGooglePlayNotification(r.Context(), token, &req)
+	writeStoreNotificationResult(w, err)
+}
+
+// AppStoreNotification recebe as App Store Server Notifications V2.
+func (h *SubscriptionHandler) AppStoreNotification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SignedPayload string `json:"signedPayload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.HandleAppStoreNotification(r.Context(), req.SignedPayload)
+	writeStoreNotificationResult(w, err)
+}
+
+// writeStoreNotificationResult responde à loja. Qualquer resposta diferente de
+// 2xx faz a loja reenviar a notificação mais tarde.
+func writeStoreNotificationResult(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidStoreNotification):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case err != nil:
+		log.Printf("failed to process store notification: %v", err)
+		http.Error(w, "failed to process notification", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
 }
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// SyncHandler é um handler para a sincronização entre aparelhos.
+type SyncHandler struct {
+	service *services.SyncService
+}
+
+// NewSyncHandler cria um novo SyncHandler.
+func NewSyncHandler(service *services.SyncService) *SyncHandler {
+	return &SyncHandler{
+		service: service,
+	}
+}
+
+// GetChanges retorna o que mudou desde ?since=<cursor>, ou o estado completo
+// sem o parâmetro. O cursor da resposta deve ser usado na próxima chamada.
+func (h *SyncHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	changes, err := h.service.GetChanges(r.Context(), userID, r.URL.Query().Get("since"))
+	if err != nil {
+		writeSyncError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Push aplica as alterações feitas no aparelho e retorna o resultado de cada
+// uma. Depois, o aparelho busca as alterações do servidor com GetChanges.
+func (h *SyncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	var push domain.SyncPush
+	if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	results, err := h.service.Push(r.Context(), userID, &push)
+	if err != nil {
+		writeSyncError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"results": results}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeSyncError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidSyncCursor), errors.Is(err, domain.ErrInvalidSyncPush):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/core/services"
 )
 
@@ -24,6 +27,8 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	type request struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		// ReferralCode é o código de indicação de outro usuário, opcional.
+		ReferralCode string `json:"referral_code"`
 	}
 
 	var req request
@@ -32,7 +37,11 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(r.Context(), req.Email, req.Password)
+	user, err := h.userService.CreateUser(r.Context(), req.Email, req.Password, req.ReferralCode)
+	if errors.Is(err, domain.ErrInvalidReferralCode) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -44,3 +53,20 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// GetReferrals retorna o código de indicação do usuário e as recompensas obtidas.
+func (h *UserHandler) GetReferrals(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	summary, err := h.userService.GetReferralSummary(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,21 +2,30 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/core/services"
 )
 
 // WeatherHandler é um handler para dados de clima.
 type WeatherHandler struct {
-	weatherService *services.WeatherService
+	weatherService      *services.WeatherService
+	entitlementService  *services.EntitlementService
+	favoriteCityService *services.FavoriteCityService
 }
 
 // NewWeatherHandler cria um novo WeatherHandler.
-func NewWeatherHandler(weatherService *services.WeatherService) *WeatherHandler {
+func NewWeatherHandler(weatherService *services.WeatherService, entitlementService *services.EntitlementService, favoriteCityService *services.FavoriteCityService) *WeatherHandler {
 	return &WeatherHandler{
-		weatherService: weatherService,
+		weatherService:      weatherService,
+		entitlementService:  entitlementService,
+		favoriteCityService: favoriteCityService,
 	}
 }
 
@@ -42,14 +51,152 @@ func (h *WeatherHandler) GetWeather(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	weatherData, err := h.weatherService.GetWeatherData(r.Context(), lat, lon)
+	// O cache agrupa as coordenadas em células e não pode receber valores fora
+	// dos limites, que virariam a previsão de outro lugar
+	if err := (domain.Coordinates{Lat: lat, Lon: lon}).Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	plan, err := h.entitlementService.GetPlan(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Perto do limite da API externa, só planos pagos disparam novas buscas
+	weatherData, err := h.weatherService.GetWeatherData(r.Context(), lat, lon, plan.Level != domain.PlanFree)
+	if errors.Is(err, domain.ErrUpstreamQuotaExhausted) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	weatherData = limitForecastDays(weatherData, plan.ForecastDays)
+
 	w.Header().Set("Content-Type", "application/json")
+	if !weatherData.FetchedAt.IsZero() {
+		w.Header().Set("Age", strconv.Itoa(int(time.Since(weatherData.FetchedAt).Seconds())))
+	}
 	if err := json.NewEncoder(w).Encode(weatherData); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// GetWeatherBatch obtém as previsões de vários locais (coordenadas ou cidades
+// favoritas) em uma única requisição, com resultado ou erro por item. Com
+// group_id no lugar de locations, os itens são as favoritas do grupo.
+func (h *WeatherHandler) GetWeatherBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Locations []domain.WeatherBatchLocation `json:"locations"`
+		GroupID   *uuid.UUID                    `json:"group_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	// O limite vale para locations; um grupo já é limitado pelo número de
+	// favoritas do plano e vem inteiro
+	favorites := make(map[uuid.UUID]*domain.FavoriteCity)
+	switch {
+	case req.GroupID != nil && len(req.Locations) > 0:
+		http.Error(w, fmt.Sprintf("%v: locations and group_id are mutually exclusive", domain.ErrInvalidWeatherBatch), http.StatusBadRequest)
+		return
+	case req.GroupID == nil && (len(req.Locations) == 0 || len(req.Locations) > domain.MaxWeatherBatchSize):
+		http.Error(w, fmt.Sprintf("%v: between 1 and %d locations are required", domain.ErrInvalidWeatherBatch, domain.MaxWeatherBatchSize), http.StatusBadRequest)
+		return
+	case req.GroupID != nil:
+		cities, err := h.favoriteCityService.GetFavoriteCitiesByUserID(r.Context(), userID, domain.FavoriteCityFilter{GroupID: req.GroupID})
+		if errors.Is(err, domain.ErrFavoriteGroupNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, city := range cities {
+			favorites[city.ID] = city
+			req.Locations = append(req.Locations, domain.WeatherBatchLocation{FavoriteID: &city.ID})
+		}
+	}
+
+	plan, err := h.entitlementService.GetPlan(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, loc := range req.Locations {
+		if loc.FavoriteID != nil && len(favorites) == 0 {
+			cities, err := h.favoriteCityService.GetFavoriteCitiesByUserID(r.Context(), userID, domain.FavoriteCityFilter{})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for _, city := range cities {
+				favorites[city.ID] = city
+			}
+			break
+		}
+	}
+
+	// Resolve cada item em coordenadas; os malformados já saem com erro
+	results := make([]*domain.WeatherBatchResult, len(req.Locations))
+	var points []domain.Coordinates
+	var pending []int
+	for i, loc := range req.Locations {
+		results[i] = &domain.WeatherBatchResult{FavoriteID: loc.FavoriteID}
+		switch {
+		case loc.FavoriteID != nil && loc.Lat == nil && loc.Lon == nil:
+			city, ok := favorites[*loc.FavoriteID]
+			if !ok {
+				results[i].Error = "favorite city not found"
+				continue
+			}
+			points = append(points, domain.Coordinates{Lat: city.Lat, Lon: city.Lon})
+		case loc.FavoriteID == nil && loc.Lat != nil && loc.Lon != nil:
+			points = append(points, domain.Coordinates{Lat: *loc.Lat, Lon: *loc.Lon})
+		default:
+			results[i].Error = "either lat and lon or favorite_id is required"
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	fetched := h.weatherService.GetWeatherBatch(r.Context(), points, plan.Level != domain.PlanFree)
+	for j, i := range pending {
+		fetched[j].FavoriteID = results[i].FavoriteID
+		if fetched[j].Weather != nil {
+			fetched[j].Weather = limitForecastDays(fetched[j].Weather, plan.ForecastDays)
+		}
+		results[i] = fetched[j]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"results": results}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// limitForecastDays limita o horizonte da previsão diária ao do plano, sem
+// alterar a instância do cache.
+func limitForecastDays(data *domain.WeatherData, days int) *domain.WeatherData {
+	if len(data.Daily) <= days {
+		return data
+	}
+	limited := *data
+	limited.Daily = data.Daily[:days]
+	return &limited
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Coordenadas inválidas são recusadas antes de chegar ao cache ou à API.
+func TestGetWeatherRejectsInvalidCoordinates(t *testing.T) {
+	h := NewWeatherHandler(nil, nil, nil)
+
+	for _, query := range []string{
+		"",
+		"lat=-23.55",
+		"lat=abc&lon=-46.63",
+		"lat=500&lon=-46.63",
+		"lat=-23.55&lon=-181",
+		"lat=NaN&lon=-46.63",
+		"lat=-23.55&lon=Inf",
+		"lat=-Inf&lon=0",
+	} {
+		rec := httptest.NewRecorder()
+		h.GetWeather(rec, httptest.NewRequest(http.MethodGet, "/weather?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /weather?%s status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
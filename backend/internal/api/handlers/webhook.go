@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// WebhookHandler é um handler para os endpoints de webhook do usuário.
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+// NewWebhookHandler cria um novo WebhookHandler.
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// GetWebhookEndpoints lista os endpoints de webhook de um usuário.
+func (h *WebhookHandler) GetWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	endpoints, err := h.service.GetEndpointsByUserID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(endpoints); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateWebhookEndpoint cadastra um endpoint de webhook. A resposta é a única
+// vez em que o segredo de assinatura é exposto.
+func (h *WebhookHandler) CreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	var endpoint domain.WebhookEndpoint
+	if err := json.NewDecoder(r.Body).Decode(&endpoint); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	endpoint.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.CreateEndpoint(r.Context(), &endpoint); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(endpoint); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// UpdateWebhookEndpoint atualiza um endpoint de webhook.
+func (h *WebhookHandler) UpdateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid webhook endpoint ID", http.StatusBadRequest)
+		return
+	}
+
+	var endpoint domain.WebhookEndpoint
+	if err := json.NewDecoder(r.Body).Decode(&endpoint); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	endpoint.UserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	endpoint.ID = id
+
+	if err := h.service.UpdateEndpoint(r.Context(), &endpoint); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteWebhookEndpoint remove um endpoint de webhook.
+func (h *WebhookHandler) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid webhook endpoint ID", http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	if err := h.service.DeleteEndpoint(r.Context(), userID, id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendTestEvent entrega um evento de teste ao endpoint e retorna o resultado.
+func (h *WebhookHandler) SendTestEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid webhook endpoint ID", http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Obter o ID do usuário a partir do contexto
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	result, err := h.service.SendTestEvent(r.Context(), userID, id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidWebhookEndpoint):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrWebhookEndpointNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
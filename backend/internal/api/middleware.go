@@ -0,0 +1,57 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/api/handlers"
+	"github.com/weatherpro/backend/internal/core/services"
+)
+
+// withAPIQuota contabiliza cada requisição na cota diária do plano do usuário.
+func withAPIQuota(entitlements *services.EntitlementService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Obter o ID do usuário a partir do contexto
+		userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+		if err := entitlements.ConsumeAPIQuota(r.Context(), userID); err != nil {
+			if !handlers.WritePlanLimitError(w, err) {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireFeature só encaminha a requisição se o plano do usuário libera o recurso.
+func requireFeature(entitlements *services.EntitlementService, feature string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// TODO: Obter o ID do usuário a partir do contexto
+		userID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+		if err := entitlements.RequireFeature(r.Context(), userID, feature); err != nil {
+			if !handlers.WritePlanLimitError(w, err) {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requireAdmin só encaminha requisições com o token administrativo no cabeçalho
+// X-Admin-Token. Sem token configurado, as rotas administrativas ficam fechadas.
+func requireAdmin(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided := r.Header.Get("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
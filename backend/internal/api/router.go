@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/weatherpro/backend/internal/api/handlers"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/core/services"
 )
 
 // NewRouter cria um novo roteador.
@@ -11,13 +13,33 @@ func NewRouter(
 	weatherHandler *handlers.WeatherHandler,
 	userHandler *handlers.UserHandler,
 	favoriteCityHandler *handlers.FavoriteCityHandler,
+	favoriteGroupHandler *handlers.FavoriteGroupHandler,
+	syncHandler *handlers.SyncHandler,
+	favoriteTransferHandler *handlers.FavoriteTransferHandler,
 	notificationSettingsHandler *handlers.NotificationSettingsHandler,
 	subscriptionHandler *handlers.SubscriptionHandler,
 	mapsHandler *handlers.MapsHandler,
-) *http.ServeMux {
+	notificationHandler *handlers.NotificationHandler,
+	alertLocationHandler *handlers.AlertLocationHandler,
+	nowcastHandler *handlers.NowcastHandler,
+	webhookHandler *handlers.WebhookHandler,
+	entitlementHandler *handlers.EntitlementHandler,
+	promoCodeHandler *handlers.PromoCodeHandler,
+	cacheAdminHandler *handlers.CacheAdminHandler,
+	entitlementService *services.EntitlementService,
+	adminToken string,
+) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/weather", weatherHandler.GetWeather)
+	mux.HandleFunc("/weather/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			weatherHandler.GetWeatherBatch(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/nowcast", requireFeature(entitlementService, domain.FeatureRadarAccess, nowcastHandler.GetNowcast))
 	mux.HandleFunc("/register", userHandler.RegisterUser)
 
 	mux.HandleFunc("/favorites", func(w http.ResponseWriter, r *http.Request) {
@@ -31,12 +53,93 @@ func NewRouter(
 		}
 	})
 
-	mux.HandleFunc("/favorites/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodDelete {
-			// Esta é uma forma simples de tratar /favorites/{id}
-			// Uma solução mais robusta usaria um roteador que suporte parâmetros de rota
-			// O restante da lógica está no handler
+	mux.HandleFunc("/favorites/order", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			favoriteCityHandler.ReorderFavoriteCities(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/favorites/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			favoriteTransferHandler.ExportFavoriteCities(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/favorites/import", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			favoriteTransferHandler.ImportFavoriteCities(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/favorites/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			favoriteCityHandler.UpdateFavoriteCity(w, r)
+		case http.MethodDelete:
 			favoriteCityHandler.DeleteFavoriteCity(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/favorites/groups", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			favoriteGroupHandler.GetFavoriteGroups(w, r)
+		case http.MethodPost:
+			favoriteGroupHandler.CreateFavoriteGroup(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/favorites/groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			favoriteGroupHandler.RenameFavoriteGroup(w, r)
+		case http.MethodDelete:
+			favoriteGroupHandler.DeleteFavoriteGroup(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/sync", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			syncHandler.GetChanges(w, r)
+		case http.MethodPost:
+			syncHandler.Push(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/notifications", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			notificationHandler.ListNotifications(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/notifications/{id}/read", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			notificationHandler.MarkNotificationAsRead(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/notifications/read-all", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			notificationHandler.MarkAllNotificationsAsRead(w, r)
 		} else {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -53,18 +156,188 @@ func NewRouter(
 		}
 	})
 
-	mux.HandleFunc("/subscription", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/alerts/locations", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			subscriptionHandler.GetSubscription(w, r)
+			alertLocationHandler.GetAlertLocations(w, r)
+		case http.MethodPost:
+			alertLocationHandler.CreateAlertLocation(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/alerts/locations/current", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			alertLocationHandler.SetCurrentLocation(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/alerts/locations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			alertLocationHandler.UpdateAlertLocation(w, r)
+		case http.MethodDelete:
+			alertLocationHandler.DeleteAlertLocation(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/webhooks/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			webhookHandler.GetWebhookEndpoints(w, r)
+		case http.MethodPost:
+			webhookHandler.CreateWebhookEndpoint(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/webhooks/endpoints/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
 		case http.MethodPut:
-			subscriptionHandler.UpdateSubscription(w, r)
+			webhookHandler.UpdateWebhookEndpoint(w, r)
+		case http.MethodDelete:
+			webhookHandler.DeleteWebhookEndpoint(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
+	mux.HandleFunc("/webhooks/endpoints/{id}/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			webhookHandler.SendTestEvent(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/subscription", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			subscriptionHandler.GetSubscription(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/subscription/verify", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.VerifySubscription(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/subscription/trial", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.StartTrial(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/subscription/checkout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.CreateCheckoutSession(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/subscription/promo-codes/redeem", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			promoCodeHandler.RedeemPromoCode(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/referrals", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			userHandler.GetReferrals(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/maps/config", mapsHandler.GetMapsConfig)
+	mux.HandleFunc("/entitlements", entitlementHandler.GetEntitlements)
+
+	// As notificações das lojas e da Stripe e as rotas administrativas têm
+	// autenticação própria e não contam na cota de nenhum usuário
+	root := http.NewServeMux()
+	root.Handle("/", withAPIQuota(entitlementService, mux))
+
+	root.HandleFunc("/admin/promo-codes", requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			promoCodeHandler.ListPromoCodes(w, r)
+		case http.MethodPost:
+			promoCodeHandler.CreatePromoCode(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	root.HandleFunc("/admin/cache/entries", requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			cacheAdminHandler.GetEntry(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	root.HandleFunc("/admin/cache/purge", requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			cacheAdminHandler.Purge(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	root.HandleFunc("/admin/cache/stats", requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			cacheAdminHandler.GetStats(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	root.HandleFunc("/admin/upstream-quota", requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			cacheAdminHandler.GetUpstreamQuota(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	root.HandleFunc("/webhooks/google-play", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.GooglePlayNotification(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	root.HandleFunc("/webhooks/app-store", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.AppStoreNotification(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	root.HandleFunc("/webhooks/stripe", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			subscriptionHandler.StripeWebhook(w, r)
+		} else {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
 
-	return mux
+	return root
 }
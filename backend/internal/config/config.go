@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 // Config contém a configuração da aplicação.
@@ -10,6 +12,81 @@ type Config struct {
 	ValkeyAddress        string
 	Port                 string
 	DatabaseURL          string
+
+	// Limites de chamadas à OpenWeatherMap (0 desliga a janela). Perto deles o
+	// backend economiza chamadas em vez de ter a chave bloqueada.
+	OpenWeatherMapCallsPerMinute int
+	OpenWeatherMapCallsPerDay    int
+
+	// Camada de cache em memória na frente do Valkey: quantidade máxima de
+	// locais e por quantos segundos cada um fica em memória.
+	CacheLocalSize       int
+	CacheLocalTTLSeconds int
+
+	// Validade das previsões: depois de CacheSoftTTLSeconds a previsão é
+	// servida desatualizada e atualizada em segundo plano; depois de
+	// CacheHardTTLSeconds ela sai do cache.
+	CacheSoftTTLSeconds int
+	CacheHardTTLSeconds int
+
+	// CacheWarmBudgetPerMinute limita as chamadas à API por minuto do job que
+	// renova as previsões de favoritos e locais de alerta (0 desliga).
+	CacheWarmBudgetPerMinute int
+
+	// Quantização das coordenadas na chave do cache: "geohash", com
+	// CacheGeohashPrecision caracteres, ou "grid", com células de
+	// CacheGridDegrees graus.
+	CacheQuantization     string
+	CacheGeohashPrecision int
+	CacheGridDegrees      float64
+
+	// CacheCodec é o formato das previsões gravadas no Valkey: "json" ou
+	// "gob", opcionalmente com "+flate". Qualquer formato continua legível.
+	CacheCodec string
+
+	// RainViewerAPIURL aponta para a lista de frames de radar (weather-maps.json).
+	RainViewerAPIURL string
+
+	// Validação de compras nas lojas. As URLs podem apontar para stubs locais.
+	// A App Store só aceita compras do AppStoreBundleID no AppStoreEnvironment
+	// ("Production" ou "Sandbox").
+	GooglePlayAPIURL         string
+	GooglePlayPackageName    string
+	GoogleServiceAccountFile string
+	AppStoreBundleID         string
+	AppStoreRootCAPath       string
+	AppStoreEnvironment      string
+	StoreProductPlans        map[string]string
+
+	// Notificações das lojas. O push do Pub/Sub é autenticado com um token OIDC
+	// emitido para GooglePubSubAudience pela conta de serviço configurada; sem
+	// as duas, todo push é recusado.
+	GoogleOAuthCertsURL        string
+	GooglePubSubAudience       string
+	GooglePubSubServiceAccount string
+
+	// Cobrança web pela Stripe. StripeAPIURL pode apontar para o stripe-mock.
+	StripeAPIURL        string
+	StripeSecretKey     string
+	StripeWebhookSecret string
+
+	// Ciclo de vida das assinaturas: período de teste do backend (uma vez por
+	// usuário) e carência de cobrança antes de expirar.
+	SubscriptionTrialPlan       string
+	SubscriptionTrialDays       int
+	SubscriptionGracePeriodDays int
+
+	// ReferralRewardDays são os dias de premium que indicador e indicado ganham
+	// quando alguém se cadastra com um código de indicação. O indicador ganha
+	// no máximo ReferralMaxRewards recompensas a cada 12 meses.
+	ReferralRewardDays int
+	ReferralMaxRewards int
+
+	// AdminAPIToken libera as rotas /admin/* pelo cabeçalho X-Admin-Token.
+	AdminAPIToken string
+
+	// NotificationRetentionDays é por quantos dias o histórico de notificações é mantido.
+	NotificationRetentionDays int
 }
 
 // Load carrega a configuração a partir de variáveis de ambiente.
@@ -19,6 +96,51 @@ func Load() *Config {
 		ValkeyAddress:        os.Getenv("VALKEY_ADDRESS"),
 		Port:                 getEnv("PORT", "8080"),
 		DatabaseURL:          os.Getenv("DATABASE_URL"),
+
+		OpenWeatherMapCallsPerMinute: getEnvInt("OPENWEATHERMAP_CALLS_PER_MINUTE", 60),
+		OpenWeatherMapCallsPerDay:    getEnvInt("OPENWEATHERMAP_CALLS_PER_DAY", 30000),
+
+		CacheLocalSize:       getEnvInt("CACHE_LOCAL_SIZE", 1000),
+		CacheLocalTTLSeconds: getEnvInt("CACHE_LOCAL_TTL_SECONDS", 30),
+		CacheSoftTTLSeconds:  getEnvInt("CACHE_SOFT_TTL_SECONDS", 1800),
+		CacheHardTTLSeconds:  getEnvInt("CACHE_HARD_TTL_SECONDS", 21600),
+
+		CacheWarmBudgetPerMinute: getEnvInt("CACHE_WARM_BUDGET_PER_MINUTE", 30),
+
+		CacheQuantization:     getEnv("CACHE_QUANTIZATION", "geohash"),
+		CacheGeohashPrecision: getEnvInt("CACHE_GEOHASH_PRECISION", 6),
+		CacheGridDegrees:      getEnvFloat("CACHE_GRID_DEGREES", 0.01),
+
+		CacheCodec: getEnv("CACHE_CODEC", "gob+flate"),
+
+		RainViewerAPIURL: getEnv("RAINVIEWER_API_URL", "https://api.rainviewer.com/public/weather-maps.json"),
+
+		GooglePlayAPIURL:         getEnv("GOOGLE_PLAY_API_URL", "https://androidpublisher.googleapis.com"),
+		GooglePlayPackageName:    os.Getenv("GOOGLE_PLAY_PACKAGE_NAME"),
+		GoogleServiceAccountFile: os.Getenv("GOOGLE_SERVICE_ACCOUNT_FILE"),
+		AppStoreBundleID:         os.Getenv("APP_STORE_BUNDLE_ID"),
+		AppStoreRootCAPath:       os.Getenv("APP_STORE_ROOT_CA_PATH"),
+		AppStoreEnvironment:      getEnv("APP_STORE_ENVIRONMENT", "Production"),
+		StoreProductPlans:        getEnvMap("STORE_PRODUCT_PLANS", "premium_monthly=premium,premium_yearly=premium,pro_monthly=pro,pro_yearly=pro"),
+
+		GoogleOAuthCertsURL:        getEnv("GOOGLE_OAUTH_CERTS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+		GooglePubSubAudience:       os.Getenv("GOOGLE_PUBSUB_AUDIENCE"),
+		GooglePubSubServiceAccount: os.Getenv("GOOGLE_PUBSUB_SERVICE_ACCOUNT"),
+
+		StripeAPIURL:        getEnv("STRIPE_API_URL", "https://api.stripe.com"),
+		StripeSecretKey:     os.Getenv("STRIPE_SECRET_KEY"),
+		StripeWebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+
+		SubscriptionTrialPlan:       getEnv("SUBSCRIPTION_TRIAL_PLAN", "premium"),
+		SubscriptionTrialDays:       getEnvInt("SUBSCRIPTION_TRIAL_DAYS", 7),
+		SubscriptionGracePeriodDays: getEnvInt("SUBSCRIPTION_GRACE_PERIOD_DAYS", 3),
+
+		ReferralRewardDays: getEnvInt("REFERRAL_REWARD_DAYS", 14),
+		ReferralMaxRewards: getEnvInt("REFERRAL_MAX_REWARDS_PER_YEAR", 10),
+
+		AdminAPIToken: os.Getenv("ADMIN_API_TOKEN"),
+
+		NotificationRetentionDays: getEnvInt("NOTIFICATION_RETENTION_DAYS", 90),
 	}
 }
 
@@ -28,3 +150,33 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// getEnvMap lê uma lista "chave=valor" separada por vírgulas.
+func getEnvMap(key, fallback string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, fallback), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok {
+			m[k] = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAlertLocationNotFound indica que o local de alerta não existe para o usuário.
+	ErrAlertLocationNotFound = errors.New("alert location not found")
+	// ErrInvalidAlertLocation indica um local de alerta malformado.
+	ErrInvalidAlertLocation = errors.New("invalid alert location")
+	// ErrAlertLocationExists indica que a cidade favorita já é um local de alerta.
+	ErrAlertLocationExists = errors.New("alert location already exists")
+)
+
+// AlertLocation representa um local para o qual o usuário recebe alertas. Ele
+// pode apontar para uma cidade favorita, para a localização atual do aparelho
+// ou para coordenadas livres.
+type AlertLocation struct {
+	ID                 uuid.UUID  `json:"id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	FavoriteCityID     *uuid.UUID `json:"favorite_city_id,omitempty"`
+	IsCurrentLocation  bool       `json:"is_current_location"`
+	Name               string     `json:"name"`
+	Lat                float64    `json:"lat"`
+	Lon                float64    `json:"lon"`
+	IsEnabled          bool       `json:"is_enabled"`
+	RainAlert          bool       `json:"rain_alert"`
+	SevereWeatherAlert bool       `json:"severe_weather_alert"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// AlertsCategory informa se o local está inscrito na categoria de alerta.
+func (l *AlertLocation) AlertsCategory(category string) bool {
+	if !l.IsEnabled {
+		return false
+	}
+	switch category {
+	case NotificationCategoryRain:
+		return l.RainAlert
+	case NotificationCategorySevereWeather:
+		return l.SevereWeatherAlert
+	default:
+		return false
+	}
+}
+
+// ValidCoordinates informa se a latitude e a longitude estão dentro dos limites.
+func ValidCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
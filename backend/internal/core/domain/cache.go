@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCacheEntryNotFound indica um local que não está no cache.
+	ErrCacheEntryNotFound = errors.New("cache entry not found")
+	// ErrInvalidCachePurge indica um pedido de limpeza do cache sem exatamente
+	// um critério válido.
+	ErrInvalidCachePurge = errors.New("invalid cache purge")
+)
+
+// CacheEntry é uma previsão guardada no cache, vista pela administração.
+type CacheEntry struct {
+	Key        string       `json:"key"`
+	TTLSeconds int64        `json:"ttl_seconds"`
+	AgeSeconds int64        `json:"age_seconds"`
+	SizeBytes  int64        `json:"size_bytes"`
+	Local      bool         `json:"local"`
+	Data       *WeatherData `json:"data"`
+}
+
+// CacheStats resume o uso do cache de clima. Os contadores somam todas as
+// réplicas; memória e idade média são estimadas por amostragem.
+type CacheStats struct {
+	Namespace         string  `json:"namespace"`
+	Hits              int64   `json:"hits"`
+	LocalHits         int64   `json:"local_hits"`
+	Misses            int64   `json:"misses"`
+	HitRatio          float64 `json:"hit_ratio"`
+	Entries           int64   `json:"entries"`
+	MemoryBytes       int64   `json:"memory_bytes"`
+	AverageAgeSeconds float64 `json:"average_age_seconds"`
+	SampledEntries    int     `json:"sampled_entries"`
+}
+
+// BoundingBox é uma região delimitada por latitudes e longitudes mínimas e máximas.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// Contains indica se a coordenada está dentro da região.
+func (b *BoundingBox) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// CachePurge escolhe as entradas a remover: uma chave de célula, um prefixo de
+// chave ou as células cujo centro cai em uma região.
+type CachePurge struct {
+	Key    string       `json:"key,omitempty"`
+	Prefix string       `json:"prefix,omitempty"`
+	Region *BoundingBox `json:"region,omitempty"`
+}
+
+// Validate exige exatamente um critério.
+func (p *CachePurge) Validate() error {
+	set := 0
+	for _, ok := range []bool{p.Key != "", p.Prefix != "", p.Region != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: exactly one of key, prefix or region is required", ErrInvalidCachePurge)
+	}
+	if r := p.Region; r != nil && (r.MinLat > r.MaxLat || r.MinLon > r.MaxLon) {
+		return fmt.Errorf("%w: region minimums must not exceed maximums", ErrInvalidCachePurge)
+	}
+	return nil
+}
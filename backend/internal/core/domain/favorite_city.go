@@ -1,13 +1,189 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxFavoriteCityNameLength = 255
+	maxFavoriteNicknameLength = 100
+	maxFavoriteTags           = 20
+	maxFavoriteTagLength      = 32
+)
+
+var (
+	// ErrFavoriteCityNotFound indica que a cidade favorita não existe para o usuário.
+	ErrFavoriteCityNotFound = errors.New("favorite city not found")
+	// ErrInvalidFavoriteCity indica uma cidade favorita malformada.
+	ErrInvalidFavoriteCity = errors.New("invalid favorite city")
+	// ErrFavoriteCityExists indica que o usuário já favoritou o mesmo ponto,
+	// com as coordenadas arredondadas a 0,01° (cerca de 1 km).
+	ErrFavoriteCityExists = errors.New("favorite city already exists")
+	// ErrInvalidFavoriteOrder indica uma reordenação que não lista exatamente
+	// as cidades favoritas do usuário.
+	ErrInvalidFavoriteOrder = errors.New("invalid favorite order")
+)
 
 // FavoriteCity representa uma cidade favorita de um usuário.
 type FavoriteCity struct {
-	ID          uuid.UUID `json:"id"`
-	UserID      uuid.UUID `json:"user_id"`
-	CityName    string    `json:"city_name"`
-	Lat         float64   `json:"lat"`
-	Lon         float64   `json:"lon"`
-	CountryCode string    `json:"country_code"`
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	CityName    string     `json:"city_name"`
+	Nickname    string     `json:"nickname,omitempty"`
+	Lat         float64    `json:"lat"`
+	Lon         float64    `json:"lon"`
+	CountryCode string     `json:"country_code"`
+	Position    int        `json:"position"`
+	GroupID     *uuid.UUID `json:"group_id,omitempty"`
+	Tags        []string   `json:"tags"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Version muda a cada alteração; a sincronização entre aparelhos a usa
+	// para detectar conflitos.
+	Version int64 `json:"version"`
+
+	// Current só é preenchido quando a listagem pede as condições atuais.
+	Current *CurrentConditions `json:"current,omitempty"`
+}
+
+// Normalize remove espaços, coloca o código do país em maiúsculas e as tags em
+// minúsculas, sem repetições.
+func (c *FavoriteCity) Normalize() {
+	c.CityName = strings.TrimSpace(c.CityName)
+	c.Nickname = strings.TrimSpace(c.Nickname)
+	c.CountryCode = strings.ToUpper(strings.TrimSpace(c.CountryCode))
+	c.Tags = NormalizeTags(c.Tags)
+}
+
+// NormalizeTags coloca as tags em minúsculas e descarta vazias e repetidas,
+// mantendo a ordem em que aparecem.
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// Validate verifica o nome, o apelido, as coordenadas e o código do país.
+func (c *FavoriteCity) Validate() error {
+	switch {
+	case c.CityName == "":
+		return fmt.Errorf("%w: city_name is required", ErrInvalidFavoriteCity)
+	case utf8.RuneCountInString(c.CityName) > maxFavoriteCityNameLength:
+		return fmt.Errorf("%w: city_name must have at most %d characters", ErrInvalidFavoriteCity, maxFavoriteCityNameLength)
+	case utf8.RuneCountInString(c.Nickname) > maxFavoriteNicknameLength:
+		return fmt.Errorf("%w: nickname must have at most %d characters", ErrInvalidFavoriteCity, maxFavoriteNicknameLength)
+	case !ValidCountryCode(c.CountryCode):
+		return fmt.Errorf("%w: country_code must be an ISO 3166-1 alpha-2 code", ErrInvalidFavoriteCity)
+	case len(c.Tags) > maxFavoriteTags:
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidFavoriteCity, maxFavoriteTags)
+	}
+	for _, tag := range c.Tags {
+		if utf8.RuneCountInString(tag) > maxFavoriteTagLength {
+			return fmt.Errorf("%w: tags must have at most %d characters", ErrInvalidFavoriteCity, maxFavoriteTagLength)
+		}
+	}
+	if err := (Coordinates{Lat: c.Lat, Lon: c.Lon}).Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFavoriteCity, err)
+	}
+	return nil
+}
+
+// FavoriteCityPatch lista os campos alterados de uma cidade favorita; campos
+// nulos ficam como estão. Um apelido vazio remove o apelido, tags substituem
+// as anteriores e "group_id": null tira a cidade do grupo.
+type FavoriteCityPatch struct {
+	CityName    *string      `json:"city_name"`
+	Nickname    *string      `json:"nickname"`
+	Lat         *float64     `json:"lat"`
+	Lon         *float64     `json:"lon"`
+	CountryCode *string      `json:"country_code"`
+	GroupID     OptionalUUID `json:"group_id"`
+	Tags        *[]string    `json:"tags"`
+}
+
+// OptionalUUID distingue um campo ausente do JSON (Set falso) de um campo
+// presente com null (Set verdadeiro e Value nulo).
+type OptionalUUID struct {
+	Set   bool
+	Value *uuid.UUID
+}
+
+// UnmarshalJSON só é chamado quando o campo está presente.
+func (o *OptionalUUID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// Apply copia para a cidade os campos presentes no patch.
+func (p *FavoriteCityPatch) Apply(c *FavoriteCity) {
+	if p.CityName != nil {
+		c.CityName = *p.CityName
+	}
+	if p.Nickname != nil {
+		c.Nickname = *p.Nickname
+	}
+	if p.Lat != nil {
+		c.Lat = *p.Lat
+	}
+	if p.Lon != nil {
+		c.Lon = *p.Lon
+	}
+	if p.CountryCode != nil {
+		c.CountryCode = *p.CountryCode
+	}
+	if p.GroupID.Set {
+		c.GroupID = p.GroupID.Value
+	}
+	if p.Tags != nil {
+		c.Tags = *p.Tags
+	}
+}
+
+// CurrentConditions resume o tempo agora em uma cidade favorita, o bastante
+// para a lista de favoritos. Error explica quando a previsão não pôde ser obtida.
+type CurrentConditions struct {
+	Temp        float64 `json:"temp"`
+	TempMin     float64 `json:"temp_min"`
+	TempMax     float64 `json:"temp_max"`
+	Icon        string  `json:"icon"`
+	Description string  `json:"description"`
+	AlertsCount int     `json:"alerts_count"`
+	Stale       bool    `json:"stale,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// NewCurrentConditions extrai o resumo das condições atuais da previsão. Sem
+// previsão diária, mínima e máxima ficam iguais à temperatura atual.
+func NewCurrentConditions(data *WeatherData) *CurrentConditions {
+	c := &CurrentConditions{
+		Temp:    data.Current.Temp,
+		TempMin: data.Current.Temp,
+		TempMax: data.Current.Temp,
+		Stale:   data.Stale,
+	}
+	if len(data.Daily) > 0 {
+		c.TempMin = data.Daily[0].Temp.Min
+		c.TempMax = data.Daily[0].Temp.Max
+	}
+	if len(data.Current.Weather) > 0 {
+		c.Icon = data.Current.Weather[0].Icon
+		c.Description = data.Current.Weather[0].Description
+	}
+	return c
 }
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const maxFavoriteGroupNameLength = 100
+
+var (
+	// ErrFavoriteGroupNotFound indica que o grupo não existe para o usuário.
+	ErrFavoriteGroupNotFound = errors.New("favorite group not found")
+	// ErrInvalidFavoriteGroup indica um grupo de favoritas malformado.
+	ErrInvalidFavoriteGroup = errors.New("invalid favorite group")
+	// ErrFavoriteGroupExists indica que o usuário já tem um grupo com o mesmo
+	// nome, sem diferenciar maiúsculas de minúsculas.
+	ErrFavoriteGroupExists = errors.New("favorite group already exists")
+)
+
+// FavoriteGroup é uma pasta com nome em que o usuário organiza suas cidades
+// favoritas (ex.: fazendas, viagens, família).
+type FavoriteGroup struct {
+	ID             uuid.UUID `json:"id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Name           string    `json:"name"`
+	FavoritesCount int       `json:"favorites_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Normalize remove os espaços das pontas do nome.
+func (g *FavoriteGroup) Normalize() {
+	g.Name = strings.TrimSpace(g.Name)
+}
+
+// Validate verifica o nome do grupo.
+func (g *FavoriteGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidFavoriteGroup)
+	}
+	if utf8.RuneCountInString(g.Name) > maxFavoriteGroupNameLength {
+		return fmt.Errorf("%w: name must have at most %d characters", ErrInvalidFavoriteGroup, maxFavoriteGroupNameLength)
+	}
+	return nil
+}
+
+// FavoriteCityFilter restringe a listagem de favoritas a um grupo e/ou a uma
+// tag. Campos vazios não filtram.
+type FavoriteCityFilter struct {
+	GroupID *uuid.UUID
+	Tag     string
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Formatos de arquivo para exportar e importar cidades favoritas.
+const (
+	FavoriteFormatGeoJSON = "geojson"
+	FavoriteFormatKML     = "kml"
+	FavoriteFormatCSV     = "csv"
+)
+
+// MaxFavoriteImportRows é o máximo de locais por arquivo importado.
+const MaxFavoriteImportRows = 500
+
+// Situação de cada linha do arquivo importado.
+const (
+	FavoriteImportImported  = "imported"
+	FavoriteImportDuplicate = "duplicate"
+	FavoriteImportFailed    = "failed"
+)
+
+var (
+	// ErrUnsupportedFavoriteFormat indica um formato de arquivo desconhecido.
+	ErrUnsupportedFavoriteFormat = errors.New("unsupported favorite file format")
+	// ErrInvalidFavoriteImport indica um arquivo ilegível, vazio ou com locais
+	// demais.
+	ErrInvalidFavoriteImport = errors.New("invalid favorite import")
+)
+
+// ValidFavoriteFormat informa se o formato é um dos suportados.
+func ValidFavoriteFormat(format string) bool {
+	switch format {
+	case FavoriteFormatGeoJSON, FavoriteFormatKML, FavoriteFormatCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// FavoriteLocationKey identifica o ponto de uma favorita com as coordenadas
+// arredondadas a 0,01°, a mesma regra que impede favoritas duplicadas.
+func FavoriteLocationKey(lat, lon float64) string {
+	round := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', 2, 64)
+	}
+	return round(lat) + "," + round(lon)
+}
+
+// Place é o resultado da geocodificação reversa de uma coordenada.
+type Place struct {
+	Name        string
+	CountryCode string
+}
+
+// FavoriteImportRow é o resultado de um local do arquivo importado. Row é a
+// posição dele no arquivo: a linha no CSV ou a ordem do local nos demais
+// formatos, começando em 1.
+type FavoriteImportRow struct {
+	Row        int        `json:"row"`
+	CityName   string     `json:"city_name,omitempty"`
+	Status     string     `json:"status"`
+	FavoriteID *uuid.UUID `json:"favorite_id,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// FavoriteImportReport resume a importação, com o resultado de cada local.
+type FavoriteImportReport struct {
+	Imported   int                  `json:"imported"`
+	Duplicates int                  `json:"duplicates"`
+	Failed     int                  `json:"failed"`
+	Rows       []*FavoriteImportRow `json:"rows"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocationInterest é um ponto acompanhado por um usuário, como cidade favorita
+// ou local de alerta. LastActiveAt é a última vez que o usuário o cadastrou ou
+// alterou.
+type LocationInterest struct {
+	Lat          float64
+	Lon          float64
+	UserID       uuid.UUID
+	LastActiveAt time.Time
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Categorias de notificação conhecidas.
+const (
+	NotificationCategoryRain          = "rain"
+	NotificationCategorySevereWeather = "severe_weather"
+	NotificationCategoryDigest        = "digest"
+)
+
+// Severidades de notificação, em ordem crescente de importância.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeveritySevere  = "severe"
+)
+
+var (
+	// ErrNotificationNotFound indica que a notificação não existe para o usuário.
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrInvalidCursor indica um cursor de paginação malformado.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Notification representa uma notificação enviada a um usuário.
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Category  string     `json:"category"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Severity  string     `json:"severity"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+
+	// DeliverAt é quando a notificação deve ser enviada ao dispositivo; nil
+	// indica que ela fica apenas na caixa de entrada.
+	DeliverAt   *time.Time `json:"deliver_at"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+}
+
+// NotificationPage representa uma página do histórico de notificações.
+type NotificationPage struct {
+	Items       []*Notification `json:"items"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+	UnreadCount int             `json:"unread_count"`
+}
+
+// SeverityRank retorna a posição da severidade na escala; valores desconhecidos
+// são tratados como informativos.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityWarning:
+		return 1
+	case SeveritySevere:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// IsValidSeverity informa se a severidade é conhecida.
+func IsValidSeverity(severity string) bool {
+	return severity == SeverityInfo || severity == SeverityWarning || severity == SeveritySevere
+}
@@ -1,6 +1,15 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidNotificationSettings indica configurações de notificação inválidas.
+var ErrInvalidNotificationSettings = errors.New("invalid notification settings")
 
 // NotificationSettings representa as configurações de notificação de um usuário.
 type NotificationSettings struct {
@@ -9,7 +18,122 @@ type NotificationSettings struct {
 	StatusBarNotification bool      `json:"status_bar_notification"`
 	RainAlert             bool      `json:"rain_alert"`
 	SevereWeatherAlert    bool      `json:"severe_weather_alert"`
-	AlertLocationName     string    `json:"alert_location_name"`
-	AlertLat              float64   `json:"alert_lat"`
-	AlertLon              float64   `json:"alert_lon"`
+
+	// Timezone é o fuso IANA do usuário (ex.: "America/Sao_Paulo"), usado para
+	// interpretar o horário silencioso e o horário do resumo diário.
+	Timezone          string                      `json:"timezone"`
+	QuietHoursEnabled bool                        `json:"quiet_hours_enabled"`
+	QuietHoursStart   string                      `json:"quiet_hours_start"`
+	QuietHoursEnd     string                      `json:"quiet_hours_end"`
+	CategoryOverrides map[string]CategoryOverride `json:"category_overrides"`
+	DigestEnabled     bool                        `json:"digest_enabled"`
+	DigestTime        string                      `json:"digest_time"`
+	LastDigestSentOn  *time.Time                  `json:"-"`
+	UpdatedAt         time.Time                   `json:"updated_at"`
+
+	// Version muda a cada alteração; a sincronização entre aparelhos a usa
+	// para detectar conflitos.
+	Version int64 `json:"version"`
+}
+
+// CategoryOverride ajusta a entrega de uma categoria de notificação.
+type CategoryOverride struct {
+	// MinSeverity descarta o envio de notificações abaixo desta severidade.
+	MinSeverity string `json:"min_severity,omitempty"`
+	// BypassQuietHours envia a categoria mesmo durante o horário silencioso.
+	BypassQuietHours bool `json:"bypass_quiet_hours"`
+}
+
+// Validate verifica o fuso horário, os horários e as severidades informadas.
+func (s *NotificationSettings) Validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidNotificationSettings, s.Timezone)
+	}
+	for _, clock := range []string{s.QuietHoursStart, s.QuietHoursEnd, s.DigestTime} {
+		if _, err := ParseClock(clock); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNotificationSettings, err)
+		}
+	}
+	for category, override := range s.CategoryOverrides {
+		if override.MinSeverity != "" && !IsValidSeverity(override.MinSeverity) {
+			return fmt.Errorf("%w: unknown severity %q for category %q", ErrInvalidNotificationSettings, override.MinSeverity, category)
+		}
+	}
+	return nil
+}
+
+// Location retorna o fuso do usuário, ou UTC quando não configurado.
+func (s *NotificationSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DeliveryTime decide quando uma notificação deve ser enviada ao dispositivo.
+// Retorna false quando ela não deve ser enviada (apenas registrada na caixa de
+// entrada). Durante o horário silencioso, o envio é adiado para o seu fim.
+func (s *NotificationSettings) DeliveryTime(n *Notification, now time.Time) (time.Time, bool) {
+	if !s.IsEnabled {
+		return time.Time{}, false
+	}
+
+	switch n.Category {
+	case NotificationCategoryRain:
+		if !s.RainAlert {
+			return time.Time{}, false
+		}
+	case NotificationCategorySevereWeather:
+		if !s.SevereWeatherAlert {
+			return time.Time{}, false
+		}
+	case NotificationCategoryDigest:
+		// O resumo já é enviado no horário escolhido pelo usuário
+		return now, true
+	}
+
+	override := s.CategoryOverrides[n.Category]
+	if override.MinSeverity != "" && SeverityRank(n.Severity) < SeverityRank(override.MinSeverity) {
+		return time.Time{}, false
+	}
+
+	if !s.QuietHoursEnabled || override.BypassQuietHours {
+		return now, true
+	}
+
+	start, errStart := ParseClock(s.QuietHoursStart)
+	end, errEnd := ParseClock(s.QuietHoursEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return now, true
+	}
+
+	local := now.In(s.Location())
+	minute := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		// Janela que atravessa a meia-noite (ex.: 22:00–07:00)
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return now, true
+	}
+
+	deliverAt := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, local.Location())
+	if !deliverAt.After(local) {
+		deliverAt = deliverAt.AddDate(0, 0, 1)
+	}
+	return deliverAt, true
+}
+
+// ParseClock converte um horário "HH:MM" em minutos desde a meia-noite.
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
 }
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliveryTime(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	at := func(loc *time.Location, day, hour, minute int) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, loc)
+	}
+
+	overnight := func() *NotificationSettings {
+		return &NotificationSettings{
+			IsEnabled:          true,
+			RainAlert:          true,
+			SevereWeatherAlert: true,
+			Timezone:           "America/Sao_Paulo",
+			QuietHoursEnabled:  true,
+			QuietHoursStart:    "22:00",
+			QuietHoursEnd:      "07:00",
+		}
+	}
+	with := func(change func(*NotificationSettings)) *NotificationSettings {
+		s := overnight()
+		change(s)
+		return s
+	}
+	rain := &Notification{Category: NotificationCategoryRain, Severity: SeverityWarning}
+
+	tests := []struct {
+		name         string
+		settings     *NotificationSettings
+		notification *Notification
+		now          time.Time
+		wantSend     bool
+		want         time.Time
+	}{
+		{name: "before the window", settings: overnight(), notification: rain, now: at(saoPaulo, 10, 21, 59), wantSend: true, want: at(saoPaulo, 10, 21, 59)},
+		{name: "window start is quiet", settings: overnight(), notification: rain, now: at(saoPaulo, 10, 22, 0), wantSend: true, want: at(saoPaulo, 11, 7, 0)},
+		{name: "before midnight waits for the next day", settings: overnight(), notification: rain, now: at(saoPaulo, 10, 23, 30), wantSend: true, want: at(saoPaulo, 11, 7, 0)},
+		{name: "after midnight waits for the same day", settings: overnight(), notification: rain, now: at(saoPaulo, 11, 3, 15), wantSend: true, want: at(saoPaulo, 11, 7, 0)},
+		{name: "window end is not quiet", settings: overnight(), notification: rain, now: at(saoPaulo, 11, 7, 0), wantSend: true, want: at(saoPaulo, 11, 7, 0)},
+		{name: "in the user's timezone, not UTC", settings: overnight(), notification: rain, now: at(time.UTC, 10, 23, 30), wantSend: true, want: at(time.UTC, 10, 23, 30)},
+		{name: "UTC instant inside the local window", settings: overnight(), notification: rain, now: at(time.UTC, 11, 3, 30), wantSend: true, want: at(saoPaulo, 11, 7, 0)},
+		{
+			name:         "daytime window",
+			settings:     with(func(s *NotificationSettings) { s.QuietHoursStart, s.QuietHoursEnd = "13:00", "15:00" }),
+			notification: rain, now: at(saoPaulo, 10, 14, 0), wantSend: true, want: at(saoPaulo, 10, 15, 0),
+		},
+		{
+			name:         "daytime window outside",
+			settings:     with(func(s *NotificationSettings) { s.QuietHoursStart, s.QuietHoursEnd = "13:00", "15:00" }),
+			notification: rain, now: at(saoPaulo, 10, 23, 0), wantSend: true, want: at(saoPaulo, 10, 23, 0),
+		},
+		{
+			name:         "empty window",
+			settings:     with(func(s *NotificationSettings) { s.QuietHoursStart, s.QuietHoursEnd = "22:00", "22:00" }),
+			notification: rain, now: at(saoPaulo, 10, 22, 0), wantSend: true, want: at(saoPaulo, 10, 22, 0),
+		},
+		{
+			name: "daylight saving starts during the night",
+			settings: with(func(s *NotificationSettings) {
+				s.Timezone = "America/New_York"
+			}),
+			notification: rain, now: at(newYork, 7, 23, 0), wantSend: true, want: at(newYork, 8, 7, 0),
+		},
+		{
+			name:         "quiet hours disabled",
+			settings:     with(func(s *NotificationSettings) { s.QuietHoursEnabled = false }),
+			notification: rain, now: at(saoPaulo, 10, 23, 0), wantSend: true, want: at(saoPaulo, 10, 23, 0),
+		},
+		{
+			name: "category bypasses quiet hours",
+			settings: with(func(s *NotificationSettings) {
+				s.CategoryOverrides = map[string]CategoryOverride{NotificationCategorySevereWeather: {BypassQuietHours: true}}
+			}),
+			notification: &Notification{Category: NotificationCategorySevereWeather, Severity: SeveritySevere},
+			now:          at(saoPaulo, 10, 23, 0), wantSend: true, want: at(saoPaulo, 10, 23, 0),
+		},
+		{
+			name: "below the category minimum severity",
+			settings: with(func(s *NotificationSettings) {
+				s.CategoryOverrides = map[string]CategoryOverride{NotificationCategoryRain: {MinSeverity: SeveritySevere}}
+			}),
+			notification: rain, now: at(saoPaulo, 10, 12, 0),
+		},
+		{name: "digest ignores quiet hours", settings: overnight(), notification: &Notification{Category: NotificationCategoryDigest, Severity: SeverityInfo}, now: at(saoPaulo, 10, 23, 0), wantSend: true, want: at(saoPaulo, 10, 23, 0)},
+		{name: "notifications disabled", settings: with(func(s *NotificationSettings) { s.IsEnabled = false }), notification: rain, now: at(saoPaulo, 10, 12, 0)},
+		{name: "rain alerts disabled", settings: with(func(s *NotificationSettings) { s.RainAlert = false }), notification: rain, now: at(saoPaulo, 10, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, send := tt.settings.DeliveryTime(tt.notification, tt.now)
+			if send != tt.wantSend {
+				t.Fatalf("DeliveryTime() send = %v, want %v", send, tt.wantSend)
+			}
+			if send && !got.Equal(tt.want) {
+				t.Fatalf("DeliveryTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		clock   string
+		want    int
+		wantErr bool
+	}{
+		{clock: "00:00", want: 0},
+		{clock: "07:30", want: 450},
+		{clock: "23:59", want: 1439},
+		{clock: "24:00", wantErr: true},
+		{clock: "07:60", wantErr: true},
+		{clock: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseClock(tt.clock)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClock(%q) = %d, %v, want %d, error %v", tt.clock, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
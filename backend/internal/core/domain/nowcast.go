@@ -0,0 +1,29 @@
+package domain
+
+// Nowcast representa a previsão de precipitação minuto a minuto para a próxima
+// hora, extrapolada a partir dos últimos frames de radar.
+type Nowcast struct {
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	RadarTime int64   `json:"radar_time"`
+
+	// Deslocamento estimado das células de chuva; a direção é para onde elas
+	// se movem, em graus a partir do norte.
+	MotionSpeedKmh     float64 `json:"motion_speed_kmh"`
+	MotionDirectionDeg float64 `json:"motion_direction_deg"`
+
+	Minutely []MinutelyForecast `json:"minutely"`
+
+	// RainStartsInMinutes é preenchido quando não chove agora e a chuva é
+	// prevista dentro do horizonte; RainEndsInMinutes, quando chove agora e a
+	// chuva deve parar.
+	RainStartsInMinutes *int    `json:"rain_starts_in_minutes"`
+	RainEndsInMinutes   *int    `json:"rain_ends_in_minutes"`
+	PeakPrecipitation   float64 `json:"peak_precipitation"`
+}
+
+// MinutelyForecast representa a precipitação prevista (mm/h) para um minuto.
+type MinutelyForecast struct {
+	Dt            int64   `json:"dt"`
+	Precipitation float64 `json:"precipitation"`
+}
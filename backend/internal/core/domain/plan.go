@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Níveis de plano, em ordem crescente.
+const (
+	PlanFree    = "free"
+	PlanPremium = "premium"
+	PlanPro     = "pro"
+)
+
+// Direitos controlados pelo plano.
+const (
+	FeatureMaxFavorites      = "max_favorites"
+	FeatureForecastDays      = "forecast_days"
+	FeatureMaxAlertLocations = "max_alert_locations"
+	FeatureDailyAPIQuota     = "daily_api_quota"
+	FeatureAdFree            = "ad_free"
+	FeatureRadarAccess       = "radar_access"
+)
+
+// ErrPlanLimit indica que o usuário atingiu um limite ou não tem acesso a um
+// recurso no seu plano. Use errors.As com *PlanLimitError para os detalhes.
+var ErrPlanLimit = errors.New("plan limit reached")
+
+// Plan descreve os direitos de um nível de assinatura.
+type Plan struct {
+	Level             string `json:"level"`
+	MaxFavorites      int    `json:"max_favorites"`
+	ForecastDays      int    `json:"forecast_days"`
+	MaxAlertLocations int    `json:"max_alert_locations"`
+	DailyAPIQuota     int    `json:"daily_api_quota"`
+	AdFree            bool   `json:"ad_free"`
+	RadarAccess       bool   `json:"radar_access"`
+}
+
+// planCatalog é o catálogo de planos, do menor para o maior.
+var planCatalog = []Plan{
+	{
+		Level:             PlanFree,
+		MaxFavorites:      3,
+		ForecastDays:      3,
+		MaxAlertLocations: 1,
+		DailyAPIQuota:     500,
+	},
+	{
+		Level:             PlanPremium,
+		MaxFavorites:      20,
+		ForecastDays:      6,
+		MaxAlertLocations: 10,
+		DailyAPIQuota:     5000,
+		AdFree:            true,
+		RadarAccess:       true,
+	},
+	{
+		Level:             PlanPro,
+		MaxFavorites:      100,
+		ForecastDays:      6,
+		MaxAlertLocations: 50,
+		DailyAPIQuota:     50000,
+		AdFree:            true,
+		RadarAccess:       true,
+	},
+}
+
+// PlanFor retorna o plano do nível informado; níveis desconhecidos recebem o
+// plano gratuito.
+func PlanFor(level string) Plan {
+	for _, plan := range planCatalog {
+		if plan.Level == level {
+			return plan
+		}
+	}
+	return planCatalog[0]
+}
+
+// Limit retorna o limite numérico do direito, ou 1/0 para direitos booleanos.
+func (p Plan) Limit(feature string) int {
+	switch feature {
+	case FeatureMaxFavorites:
+		return p.MaxFavorites
+	case FeatureForecastDays:
+		return p.ForecastDays
+	case FeatureMaxAlertLocations:
+		return p.MaxAlertLocations
+	case FeatureDailyAPIQuota:
+		return p.DailyAPIQuota
+	case FeatureAdFree:
+		return boolLimit(p.AdFree)
+	case FeatureRadarAccess:
+		return boolLimit(p.RadarAccess)
+	default:
+		return 0
+	}
+}
+
+// UpgradeFor retorna o menor plano acima do atual que oferece mais do direito
+// informado, ou "" se não houver.
+func (p Plan) UpgradeFor(feature string) string {
+	current := p.Limit(feature)
+	above := false
+	for _, plan := range planCatalog {
+		if plan.Level == p.Level {
+			above = true
+			continue
+		}
+		if above && plan.Limit(feature) > current {
+			return plan.Level
+		}
+	}
+	return ""
+}
+
+// PlanLimitError descreve o limite atingido e o plano que o amplia.
+type PlanLimitError struct {
+	Feature     string `json:"feature"`
+	Limit       int    `json:"limit"`
+	CurrentPlan string `json:"current_plan"`
+	UpgradeTo   string `json:"upgrade_to,omitempty"`
+}
+
+func (e *PlanLimitError) Error() string {
+	return fmt.Sprintf("%s: %s (limit %d on plan %s)", ErrPlanLimit, e.Feature, e.Limit, e.CurrentPlan)
+}
+
+// Is permite comparar com ErrPlanLimit via errors.Is.
+func (e *PlanLimitError) Is(target error) bool {
+	return target == ErrPlanLimit
+}
+
+// IsBooleanFeature informa se o direito é um recurso liberado ou não, em vez
+// de um limite numérico.
+func IsBooleanFeature(feature string) bool {
+	return feature == FeatureAdFree || feature == FeatureRadarAccess
+}
+
+func boolLimit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
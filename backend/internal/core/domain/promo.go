@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de código promocional.
+const (
+	// PromoKindPercent dá um desconto percentual no checkout web.
+	PromoKindPercent = "percent"
+	// PromoKindDuration concede dias do plano ao ser resgatado.
+	PromoKindDuration = "duration"
+)
+
+var (
+	// ErrPromoCodeNotFound indica um código promocional inexistente.
+	ErrPromoCodeNotFound = errors.New("promo code not found")
+	// ErrPromoCodeExpired indica um código promocional vencido.
+	ErrPromoCodeExpired = errors.New("promo code expired")
+	// ErrPromoCodeExhausted indica um código que atingiu o máximo de resgates.
+	ErrPromoCodeExhausted = errors.New("promo code has no redemptions left")
+	// ErrPromoCodeAlreadyRedeemed indica que o usuário já usou o código.
+	ErrPromoCodeAlreadyRedeemed = errors.New("promo code already redeemed")
+	// ErrPromoCodeNotApplicable indica um código usado no lugar errado (ex.: um
+	// desconto percentual resgatado fora do checkout).
+	ErrPromoCodeNotApplicable = errors.New("promo code not applicable")
+	// ErrInvalidPromoCode indica dados inválidos ao criar um código.
+	ErrInvalidPromoCode = errors.New("invalid promo code")
+	// ErrPromoCodeExists indica um código já cadastrado.
+	ErrPromoCodeExists = errors.New("promo code already exists")
+)
+
+// PromoCode é um código promocional de campanha.
+type PromoCode struct {
+	ID           uuid.UUID `json:"id"`
+	Code         string    `json:"code"`
+	Kind         string    `json:"kind"`
+	PercentOff   int       `json:"percent_off,omitempty"`
+	DurationDays int       `json:"duration_days,omitempty"`
+	PlanLevel    string    `json:"plan_level"`
+	// MaxRedemptions nulo permite resgates ilimitados.
+	MaxRedemptions  *int       `json:"max_redemptions"`
+	RedemptionCount int        `json:"redemption_count"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	// StripeCouponID é o cupom da Stripe aplicado no checkout dos códigos percentuais.
+	StripeCouponID string    `json:"stripe_coupon_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Validate normaliza e valida um código antes de cadastrá-lo.
+func (p *PromoCode) Validate() error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if p.Code == "" || len(p.Code) > 64 {
+		return fmt.Errorf("%w: code must have 1 to 64 characters", ErrInvalidPromoCode)
+	}
+	if p.PlanLevel == "" {
+		p.PlanLevel = PlanPremium
+	}
+	if p.PlanLevel == PlanFree || PlanFor(p.PlanLevel).Level != p.PlanLevel {
+		return fmt.Errorf("%w: plan_level must be a paid plan", ErrInvalidPromoCode)
+	}
+	if p.MaxRedemptions != nil && *p.MaxRedemptions < 1 {
+		return fmt.Errorf("%w: max_redemptions must be positive", ErrInvalidPromoCode)
+	}
+
+	switch p.Kind {
+	case PromoKindPercent:
+		if p.PercentOff < 1 || p.PercentOff > 100 {
+			return fmt.Errorf("%w: percent_off must be between 1 and 100", ErrInvalidPromoCode)
+		}
+		if p.StripeCouponID == "" {
+			return fmt.Errorf("%w: stripe_coupon_id is required for percent codes", ErrInvalidPromoCode)
+		}
+		p.DurationDays = 0
+	case PromoKindDuration:
+		if p.DurationDays < 1 || p.DurationDays > 366 {
+			return fmt.Errorf("%w: duration_days must be between 1 and 366", ErrInvalidPromoCode)
+		}
+		p.PercentOff = 0
+	default:
+		return fmt.Errorf("%w: kind must be percent or duration", ErrInvalidPromoCode)
+	}
+	return nil
+}
+
+// Redeemable verifica validade e disponibilidade do código no instante informado.
+func (p *PromoCode) Redeemable(now time.Time) error {
+	if p.ExpiresAt != nil && !now.Before(*p.ExpiresAt) {
+		return ErrPromoCodeExpired
+	}
+	if p.MaxRedemptions != nil && p.RedemptionCount >= *p.MaxRedemptions {
+		return ErrPromoCodeExhausted
+	}
+	return nil
+}
+
+// ErrInvalidReferralCode indica um código de indicação inexistente.
+var ErrInvalidReferralCode = errors.New("invalid referral code")
+
+// ReferralSummary resume o programa de indicação de um usuário.
+type ReferralSummary struct {
+	Code          string `json:"code"`
+	ReferredCount int    `json:"referred_count"`
+	RewardDays    int    `json:"reward_days"`
+}
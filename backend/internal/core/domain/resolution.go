@@ -0,0 +1,24 @@
+package domain
+
+// Métodos de quantização das coordenadas usadas como chave do cache de clima.
+const (
+	ResolutionGeohash = "geohash"
+	ResolutionGrid    = "grid"
+)
+
+// Resolution descreve a célula em que uma coordenada caiu. Todas as coordenadas
+// da célula compartilham a mesma previsão, buscada no centro dela.
+type Resolution struct {
+	Method string `json:"method"`
+	// Precision é o número de caracteres do geohash; GridDegrees, o lado da
+	// célula em graus. Só um dos dois é preenchido, conforme o método.
+	Precision   int     `json:"precision,omitempty"`
+	GridDegrees float64 `json:"grid_degrees,omitempty"`
+	Cell        string  `json:"cell"`
+	// Lat e Lon são o centro da célula.
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	// Dimensões aproximadas da célula na latitude do centro.
+	WidthMeters  float64 `json:"width_meters"`
+	HeightMeters float64 `json:"height_meters"`
+}
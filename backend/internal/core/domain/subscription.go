@@ -1,11 +1,103 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Provedores de assinatura.
+const (
+	ProviderGooglePlay = "google_play"
+	ProviderAppStore   = "app_store"
+	ProviderStripe     = "stripe"
+	// ProviderTrial identifica o período de teste concedido pelo próprio backend.
+	ProviderTrial = "trial"
+	// ProviderPromo identifica dias concedidos por promoções e indicações a quem
+	// não tem uma assinatura cobrada.
+	ProviderPromo = "promo"
+)
+
+// IsBilledProvider informa se o provedor cobra e renova a assinatura por conta
+// própria (lojas e Stripe), em oposição ao tempo concedido pelo backend.
+func IsBilledProvider(provider string) bool {
+	switch provider {
+	case ProviderGooglePlay, ProviderAppStore, ProviderStripe:
+		return true
+	}
+	return false
+}
+
+// Estados do ciclo de vida de uma assinatura.
+const (
+	// SubscriptionTrialing é um período de teste gratuito com direito ao plano.
+	SubscriptionTrialing = "trialing"
+	// SubscriptionActive é uma assinatura paga e em dia.
+	SubscriptionActive = "active"
+	// SubscriptionInGrace é uma renovação não paga que ainda mantém o plano até
+	// GracePeriodEndsAt.
+	SubscriptionInGrace = "in_grace"
+	// SubscriptionOnHold é uma assinatura suspensa por falta de pagamento ou
+	// pausa; não dá direito ao plano, mas pode voltar a ativa.
+	SubscriptionOnHold = "on_hold"
+	// SubscriptionCanceled teve a renovação desligada e mantém o plano até o
+	// fim do período já pago.
+	SubscriptionCanceled = "canceled"
+	// SubscriptionExpired encerrou e não dá mais direito ao plano.
+	SubscriptionExpired = "expired"
+)
+
+// subscriptionTransitions lista, para cada estado, os estados seguintes
+// permitidos. Permanecer no mesmo estado (ex.: uma renovação) é sempre permitido.
+var subscriptionTransitions = map[string][]string{
+	"":                   {SubscriptionTrialing, SubscriptionActive, SubscriptionInGrace, SubscriptionOnHold, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionTrialing: {SubscriptionActive, SubscriptionInGrace, SubscriptionOnHold, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionActive:   {SubscriptionInGrace, SubscriptionOnHold, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionInGrace:  {SubscriptionActive, SubscriptionOnHold, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionOnHold:   {SubscriptionActive, SubscriptionCanceled, SubscriptionExpired},
+	SubscriptionCanceled: {SubscriptionActive, SubscriptionExpired},
+	SubscriptionExpired:  {SubscriptionTrialing, SubscriptionActive},
+}
+
+// CanTransition informa se a assinatura pode passar do estado from para to.
+func CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range subscriptionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Eventos de ciclo de vida registrados pelo próprio backend em subscription_events.
+const (
+	SubscriptionEventTrialStarted       = "lifecycle.trial_started"
+	SubscriptionEventGracePeriodStarted = "lifecycle.grace_period_started"
+	SubscriptionEventExpired            = "lifecycle.expired"
+	SubscriptionEventTransitionRejected = "lifecycle.transition_rejected"
+	SubscriptionEventGrantPromoCode     = "grant.promo_code"
+	SubscriptionEventGrantReferral      = "grant.referral"
+)
+
+var (
+	// ErrInvalidPurchase indica uma compra que não pôde ser validada junto à loja.
+	ErrInvalidPurchase = errors.New("invalid purchase")
+	// ErrPurchaseInUse indica uma compra já vinculada a outro usuário.
+	ErrPurchaseInUse = errors.New("purchase already linked to another user")
+	// ErrInvalidStoreNotification indica uma notificação de loja não autenticada
+	// ou malformada.
+	ErrInvalidStoreNotification = errors.New("invalid store notification")
+	// ErrInvalidTransition indica uma mudança de estado não permitida.
+	ErrInvalidTransition = errors.New("invalid subscription state transition")
+	// ErrTrialAlreadyUsed indica que o usuário já usou seu período de teste.
+	ErrTrialAlreadyUsed = errors.New("free trial already used")
+)
+
 // Subscription representa a assinatura de um usuário.
 type Subscription struct {
 	UserID                 uuid.UUID `json:"user_id"`
@@ -13,4 +105,76 @@ type Subscription struct {
 	CurrentPeriodEndsAt    time.Time `json:"current_period_ends_at"`
 	Provider               string    `json:"provider"`
 	ProviderSubscriptionID string    `json:"provider_subscription_id"`
+	Status                 string    `json:"status"`
+	// GracePeriodEndsAt é até quando uma assinatura in_grace ou on_hold espera
+	// pelo pagamento antes de expirar.
+	GracePeriodEndsAt *time.Time `json:"grace_period_ends_at,omitempty"`
+	// TrialUsedAt registra o primeiro período de teste do usuário, que só pode
+	// acontecer uma vez.
+	TrialUsedAt *time.Time `json:"trial_used_at,omitempty"`
+	// GrantedDays são dias concedidos por promoções e indicações que se somam
+	// ao período informado pelo provedor cobrado.
+	GrantedDays int `json:"granted_days,omitempty"`
+}
+
+// EffectivePlan retorna o nível de plano a que a assinatura dá direito agora.
+func (s *Subscription) EffectivePlan(now time.Time) string {
+	switch s.Status {
+	case SubscriptionTrialing, SubscriptionActive:
+		return s.PlanLevel
+	case SubscriptionInGrace:
+		if s.GracePeriodEndsAt == nil || now.Before(*s.GracePeriodEndsAt) {
+			return s.PlanLevel
+		}
+	case SubscriptionCanceled:
+		if now.Before(s.CurrentPeriodEndsAt) {
+			return s.PlanLevel
+		}
+	}
+	return PlanFree
+}
+
+// PurchaseVerification é a prova de compra enviada pelo app para validação.
+type PurchaseVerification struct {
+	Provider string `json:"provider"`
+	// PurchaseToken é o token de compra da Google Play.
+	PurchaseToken string `json:"purchase_token"`
+	// SignedTransaction é a transação assinada (JWS) do StoreKit 2.
+	SignedTransaction string `json:"signed_transaction"`
+}
+
+// CheckoutRequest pede uma sessão de checkout web para um preço da Stripe.
+type CheckoutRequest struct {
+	PriceID    string `json:"price_id"`
+	SuccessURL string `json:"success_url"`
+	CancelURL  string `json:"cancel_url"`
+	Email      string `json:"email"`
+	// PromoCode é um código promocional percentual, opcional.
+	PromoCode string `json:"promo_code"`
+}
+
+// CheckoutSession é a sessão de checkout para a qual o usuário é redirecionado.
+type CheckoutSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// SubscriptionEvent é um registro de auditoria, somente inserção, de um evento
+// que afetou (ou poderia afetar) uma assinatura.
+type SubscriptionEvent struct {
+	ID                     uuid.UUID  `json:"id"`
+	UserID                 *uuid.UUID `json:"user_id"`
+	Provider               string     `json:"provider"`
+	ProviderSubscriptionID string     `json:"provider_subscription_id"`
+	// ProviderEventID identifica o evento na origem e evita registros duplicados
+	// quando a loja reenvia a notificação.
+	ProviderEventID     string          `json:"provider_event_id"`
+	EventType           string          `json:"event_type"`
+	EventSubtype        string          `json:"event_subtype"`
+	PlanLevel           string          `json:"plan_level"`
+	CurrentPeriodEndsAt *time.Time      `json:"current_period_ends_at"`
+	StatusFrom          string          `json:"status_from"`
+	StatusTo            string          `json:"status_to"`
+	Payload             json.RawMessage `json:"payload"`
+	CreatedAt           time.Time       `json:"created_at"`
 }
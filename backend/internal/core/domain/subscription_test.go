@@ -0,0 +1,52 @@
+package domain
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{from: "", to: SubscriptionTrialing, want: true},
+		{from: "", to: SubscriptionActive, want: true},
+		{from: SubscriptionTrialing, to: SubscriptionActive, want: true},
+		{from: SubscriptionTrialing, to: SubscriptionExpired, want: true},
+		{from: SubscriptionActive, to: SubscriptionActive, want: true},
+		{from: SubscriptionActive, to: SubscriptionInGrace, want: true},
+		{from: SubscriptionActive, to: SubscriptionCanceled, want: true},
+		{from: SubscriptionInGrace, to: SubscriptionActive, want: true},
+		{from: SubscriptionInGrace, to: SubscriptionOnHold, want: true},
+		{from: SubscriptionOnHold, to: SubscriptionActive, want: true},
+		{from: SubscriptionCanceled, to: SubscriptionActive, want: true},
+		{from: SubscriptionCanceled, to: SubscriptionExpired, want: true},
+		{from: SubscriptionExpired, to: SubscriptionActive, want: true},
+		{from: SubscriptionExpired, to: SubscriptionExpired, want: true},
+
+		{from: SubscriptionActive, to: SubscriptionTrialing},
+		{from: SubscriptionOnHold, to: SubscriptionInGrace},
+		{from: SubscriptionOnHold, to: SubscriptionTrialing},
+		{from: SubscriptionCanceled, to: SubscriptionInGrace},
+		{from: SubscriptionCanceled, to: SubscriptionOnHold},
+		{from: SubscriptionExpired, to: SubscriptionInGrace},
+		{from: SubscriptionExpired, to: SubscriptionCanceled},
+		{from: SubscriptionActive, to: ""},
+		{from: SubscriptionActive, to: "paused"},
+		{from: "paused", to: SubscriptionActive},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// Todo estado alcançável precisa ter saída para expired, para que nenhuma
+// assinatura fique presa com direito ao plano.
+func TestEveryStateCanExpire(t *testing.T) {
+	for from := range subscriptionTransitions {
+		if !CanTransition(from, SubscriptionExpired) {
+			t.Errorf("CanTransition(%q, expired) = false", from)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entidades sincronizadas entre aparelhos.
+const (
+	SyncEntityFavoriteCity         = "favorite_city"
+	SyncEntityNotificationSettings = "notification_settings"
+)
+
+// Situação de cada alteração enviada pelo aparelho.
+const (
+	// SyncStatusApplied indica que a alteração foi gravada.
+	SyncStatusApplied = "applied"
+	// SyncStatusConflict indica que o registro mudou no servidor depois da
+	// versão que o aparelho conhecia; Winner diz qual lado prevaleceu.
+	SyncStatusConflict = "conflict"
+	// SyncStatusRejected indica uma alteração inválida, que não foi gravada.
+	SyncStatusRejected = "rejected"
+)
+
+// Lado que prevaleceu em um conflito: a alteração mais recente vence.
+const (
+	SyncWinnerClient = "client"
+	SyncWinnerServer = "server"
+)
+
+// MaxSyncChanges é o máximo de alterações por envio.
+const MaxSyncChanges = 500
+
+var (
+	// ErrInvalidSyncCursor indica um cursor malformado ou à frente do servidor.
+	ErrInvalidSyncCursor = errors.New("invalid sync cursor")
+	// ErrInvalidSyncPush indica um envio vazio, grande demais ou malformado.
+	ErrInvalidSyncPush = errors.New("invalid sync push")
+	// ErrSyncVersionMismatch indica que o registro mudou entre a leitura e a
+	// gravação de uma alteração sincronizada.
+	ErrSyncVersionMismatch = errors.New("sync version mismatch")
+)
+
+// ParseSyncCursor lê o cursor devolvido por uma sincronização anterior.
+func ParseSyncCursor(cursor string) (int64, error) {
+	version, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || version < 0 {
+		return 0, ErrInvalidSyncCursor
+	}
+	return version, nil
+}
+
+// FormatSyncCursor gera o cursor da versão informada.
+func FormatSyncCursor(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// SyncTombstone registra um registro apagado.
+type SyncTombstone struct {
+	Entity    string    `json:"entity"`
+	ID        uuid.UUID `json:"id"`
+	Version   int64     `json:"version"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncChanges é o que mudou para o usuário desde o cursor. Sem cursor, Full
+// indica que a resposta traz o estado completo e substitui o local.
+type SyncChanges struct {
+	Cursor               string                `json:"cursor"`
+	Full                 bool                  `json:"full"`
+	Favorites            []*FavoriteCity       `json:"favorites"`
+	Deleted              []*SyncTombstone      `json:"deleted"`
+	NotificationSettings *NotificationSettings `json:"notification_settings,omitempty"`
+}
+
+// SyncChange traz os metadados de uma alteração feita no aparelho.
+// BaseVersion é a versão do registro que o aparelho tinha ao alterá-lo (0 para
+// registros novos) e ModifiedAt, quando a alteração foi feita.
+type SyncChange struct {
+	BaseVersion int64     `json:"base_version"`
+	ModifiedAt  time.Time `json:"modified_at"`
+}
+
+// Validate verifica os metadados da alteração.
+func (c SyncChange) Validate() error {
+	if c.BaseVersion < 0 {
+		return fmt.Errorf("%w: base_version must not be negative", ErrInvalidSyncPush)
+	}
+	return nil
+}
+
+// FavoriteCityChange é uma cidade favorita criada, alterada ou apagada no
+// aparelho. O registro vem completo, com o ID gerado pelo aparelho quando novo.
+type FavoriteCityChange struct {
+	FavoriteCity
+	SyncChange
+	Deleted bool `json:"deleted"`
+}
+
+// NotificationSettingsChange são as configurações alteradas no aparelho.
+type NotificationSettingsChange struct {
+	NotificationSettings
+	SyncChange
+}
+
+// SyncPush são as alterações feitas no aparelho desde a última sincronização.
+type SyncPush struct {
+	Favorites            []*FavoriteCityChange       `json:"favorites"`
+	NotificationSettings *NotificationSettingsChange `json:"notification_settings"`
+}
+
+// SyncChangeResult informa o que aconteceu com uma alteração enviada. Version
+// é a nova versão do registro, a ser usada como base na próxima alteração. Em
+// conflitos vencidos pelo servidor, Server traz o registro que prevaleceu
+// (nulo se ele foi apagado).
+type SyncChangeResult struct {
+	Entity  string    `json:"entity"`
+	ID      uuid.UUID `json:"id"`
+	Status  string    `json:"status"`
+	Winner  string    `json:"winner,omitempty"`
+	Version int64     `json:"version,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Server  any       `json:"server,omitempty"`
+}
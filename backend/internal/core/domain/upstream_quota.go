@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUpstreamQuotaExhausted indica que a cota da API externa acabou e não há
+// previsão em cache para servir no lugar.
+var ErrUpstreamQuotaExhausted = errors.New("upstream api quota exhausted")
+
+// Níveis de consumo da cota de uma API externa. A partir de conserve o backend
+// economiza chamadas; em critical só usuários pagantes disparam novas buscas;
+// em exhausted ninguém dispara.
+const (
+	QuotaLevelNormal    = "normal"
+	QuotaLevelConserve  = "conserve"
+	QuotaLevelCritical  = "critical"
+	QuotaLevelExhausted = "exhausted"
+)
+
+// Frações da cota que disparam cada nível.
+const (
+	quotaConserveRatio = 0.7
+	quotaCriticalRatio = 0.9
+)
+
+var quotaLevelRank = map[string]int{
+	QuotaLevelNormal:    0,
+	QuotaLevelConserve:  1,
+	QuotaLevelCritical:  2,
+	QuotaLevelExhausted: 3,
+}
+
+// QuotaWindowUsage é o consumo de uma janela da cota.
+type QuotaWindowUsage struct {
+	Window    string `json:"window"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+	Remaining int64  `json:"remaining"`
+}
+
+// UpstreamQuotaStatus é o consumo da cota de uma API externa em todas as janelas.
+type UpstreamQuotaStatus struct {
+	Provider  string             `json:"provider"`
+	Level     string             `json:"level"`
+	Windows   []QuotaWindowUsage `json:"windows"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// QuotaLevel retorna o nível da janela mais consumida. Janelas sem limite são
+// ignoradas.
+func QuotaLevel(windows []QuotaWindowUsage) string {
+	level := QuotaLevelNormal
+	for _, w := range windows {
+		if w.Limit <= 0 {
+			continue
+		}
+		ratio := float64(w.Used) / float64(w.Limit)
+		var l string
+		switch {
+		case ratio >= 1:
+			l = QuotaLevelExhausted
+		case ratio >= quotaCriticalRatio:
+			l = QuotaLevelCritical
+		case ratio >= quotaConserveRatio:
+			l = QuotaLevelConserve
+		default:
+			l = QuotaLevelNormal
+		}
+		if QuotaLevelAtLeast(l, level) {
+			level = l
+		}
+	}
+	return level
+}
+
+// QuotaLevelAtLeast indica se level é igual ou mais grave que threshold.
+func QuotaLevelAtLeast(level, threshold string) bool {
+	return quotaLevelRank[level] >= quotaLevelRank[threshold]
+}
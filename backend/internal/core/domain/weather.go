@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type WeatherData struct {
 	Lat            float64         `json:"lat"`
 	Lon            float64         `json:"lon"`
@@ -8,6 +10,11 @@ type WeatherData struct {
 	Current        CurrentWeather  `json:"current"`
 	Hourly         []HourlyForecast `json:"hourly"`
 	Daily          []DailyForecast  `json:"daily"`
+	Resolution     *Resolution      `json:"resolution,omitempty"`
+	// FetchedAt é quando a previsão foi obtida da API; Stale indica que ela
+	// passou da validade e está sendo servida enquanto é atualizada.
+	FetchedAt      time.Time        `json:"fetched_at"`
+	Stale          bool             `json:"stale,omitempty"`
 }
 
 type CurrentWeather struct {
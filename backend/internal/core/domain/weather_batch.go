@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxWeatherBatchSize é o máximo de locais informados em locations numa
+// consulta em lote. Consultas por grupo não têm esse limite.
+const MaxWeatherBatchSize = 25
+
+var (
+	// ErrInvalidCoordinates indica latitude fora de [-90, 90], longitude
+	// fora de [-180, 180] ou um valor que não é número.
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+	// ErrInvalidWeatherBatch indica uma consulta em lote vazia, grande demais
+	// ou com itens malformados.
+	ErrInvalidWeatherBatch = errors.New("invalid weather batch")
+)
+
+// Coordinates é um par de latitude e longitude.
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Validate verifica se as coordenadas estão dentro dos limites. A condição é
+// escrita pela positiva para que NaN, que falha em qualquer comparação, seja
+// rejeitado.
+func (c Coordinates) Validate() error {
+	if !(c.Lat >= -90 && c.Lat <= 90 && c.Lon >= -180 && c.Lon <= 180) {
+		return fmt.Errorf("%w: lat must be within [-90, 90] and lon within [-180, 180]", ErrInvalidCoordinates)
+	}
+	return nil
+}
+
+// WeatherBatchLocation é um item da consulta em lote: coordenadas ou o ID de
+// uma cidade favorita do usuário.
+type WeatherBatchLocation struct {
+	Lat        *float64   `json:"lat,omitempty"`
+	Lon        *float64   `json:"lon,omitempty"`
+	FavoriteID *uuid.UUID `json:"favorite_id,omitempty"`
+}
+
+// WeatherBatchResult é o resultado de um item da consulta em lote, na mesma
+// posição do pedido. Cada item tem a previsão ou o erro que impediu obtê-la.
+type WeatherBatchResult struct {
+	Lat        float64      `json:"lat"`
+	Lon        float64      `json:"lon"`
+	FavoriteID *uuid.UUID   `json:"favorite_id,omitempty"`
+	Weather    *WeatherData `json:"weather,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCoordinatesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       Coordinates
+		wantErr bool
+	}{
+		{name: "origin", c: Coordinates{Lat: 0, Lon: 0}},
+		{name: "são paulo", c: Coordinates{Lat: -23.5505, Lon: -46.6333}},
+		{name: "poles and antimeridian", c: Coordinates{Lat: 90, Lon: -180}},
+		{name: "south pole and antimeridian", c: Coordinates{Lat: -90, Lon: 180}},
+		{name: "lat above range", c: Coordinates{Lat: 90.0001, Lon: 0}, wantErr: true},
+		{name: "lat below range", c: Coordinates{Lat: -90.0001, Lon: 0}, wantErr: true},
+		{name: "lon above range", c: Coordinates{Lat: 0, Lon: 180.0001}, wantErr: true},
+		{name: "lon below range", c: Coordinates{Lat: 0, Lon: -180.0001}, wantErr: true},
+		{name: "NaN lat", c: Coordinates{Lat: math.NaN(), Lon: 0}, wantErr: true},
+		{name: "NaN lon", c: Coordinates{Lat: 0, Lon: math.NaN()}, wantErr: true},
+		{name: "infinite lat", c: Coordinates{Lat: math.Inf(1), Lon: 0}, wantErr: true},
+		{name: "negative infinite lon", c: Coordinates{Lat: 0, Lon: math.Inf(-1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.c.Validate()
+			if tt.wantErr && !errors.Is(err, ErrInvalidCoordinates) {
+				t.Fatalf("Validate() error = %v, want ErrInvalidCoordinates", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFavoriteCityValidateRejectsNaN(t *testing.T) {
+	city := &FavoriteCity{CityName: "São Paulo", CountryCode: "BR", Lat: math.NaN(), Lon: -46.6333}
+	if err := city.Validate(); !errors.Is(err, ErrInvalidFavoriteCity) || !errors.Is(err, ErrInvalidCoordinates) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidFavoriteCity wrapping ErrInvalidCoordinates", err)
+	}
+}
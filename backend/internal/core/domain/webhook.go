@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento entregues por webhooks.
+const (
+	WebhookEventTest = "webhook.test"
+)
+
+var (
+	// ErrWebhookEndpointNotFound indica que o endpoint não existe para o usuário.
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrInvalidWebhookEndpoint indica um endpoint de webhook malformado.
+	ErrInvalidWebhookEndpoint = errors.New("invalid webhook endpoint")
+)
+
+// WebhookEndpoint representa um endpoint HTTP cadastrado pelo usuário para
+// receber eventos de alerta.
+type WebhookEndpoint struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	URL    string    `json:"url"`
+	// Secret é usado para assinar as entregas; só é exposto na criação.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes filtra os eventos entregues. Vazio recebe todos; "alert.*"
+	// recebe todos os alertas.
+	EventTypes          []string   `json:"event_types"`
+	IsEnabled           bool       `json:"is_enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastFailureAt       *time.Time `json:"last_failure_at"`
+	LastFailureReason   string     `json:"last_failure_reason,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// Accepts informa se o endpoint está inscrito no tipo de evento.
+func (e *WebhookEndpoint) Accepts(eventType string) bool {
+	if len(e.EventTypes) == 0 || eventType == WebhookEventTest {
+		return true
+	}
+	for _, t := range e.EventTypes {
+		if t == eventType || (strings.HasSuffix(t, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent é o envelope JSON entregue aos endpoints. Data já vem
+// serializado, para que a entrega não leia objetos que o chamador ainda altera.
+type WebhookEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery é a entrega pendente de um evento a um endpoint. Attempts
+// conta as tentativas já feitas.
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	Endpoint      *WebhookEndpoint
+	Event         *WebhookEvent
+	Attempts      int
+	NextAttemptAt time.Time
+}
+
+// WebhookDeliveryResult descreve o resultado de uma tentativa de entrega.
+type WebhookDeliveryResult struct {
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NotificationEventType retorna o tipo de evento de webhook de uma categoria de
+// notificação (ex.: "alert.rain").
+func NotificationEventType(category string) string {
+	if category == NotificationCategoryDigest {
+		return "notification.digest"
+	}
+	return "alert." + category
+}
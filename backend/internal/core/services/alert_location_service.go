@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// AlertLocationService é um serviço para os locais de alerta dos usuários.
+type AlertLocationService struct {
+	repo             *database.AlertLocationRepository
+	favoriteCityRepo *database.FavoriteCityRepository
+	entitlements     *EntitlementService
+}
+
+// NewAlertLocationService cria uma nova instância de AlertLocationService.
+func NewAlertLocationService(repo *database.AlertLocationRepository, favoriteCityRepo *database.FavoriteCityRepository, entitlements *EntitlementService) *AlertLocationService {
+	return &AlertLocationService{
+		repo:             repo,
+		favoriteCityRepo: favoriteCityRepo,
+		entitlements:     entitlements,
+	}
+}
+
+// GetAlertLocationsByUserID obtém todos os locais de alerta de um usuário.
+func (s *AlertLocationService) GetAlertLocationsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.AlertLocation, error) {
+	return s.repo.GetAlertLocationsByUserID(ctx, userID)
+}
+
+// CreateAlertLocation cria um local de alerta vinculado a uma cidade favorita do
+// usuário ou a coordenadas livres, respeitando o limite do plano.
+func (s *AlertLocationService) CreateAlertLocation(ctx context.Context, loc *domain.AlertLocation) error {
+	loc.IsCurrentLocation = false
+
+	count, err := s.repo.CountAlertLocationsByUserID(ctx, loc.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.entitlements.CheckLimit(ctx, loc.UserID, domain.FeatureMaxAlertLocations, count); err != nil {
+		return err
+	}
+
+	if loc.FavoriteCityID != nil {
+		city, err := s.favoriteCityRepo.GetFavoriteCityByID(ctx, *loc.FavoriteCityID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && city.UserID != loc.UserID) {
+			return fmt.Errorf("%w: favorite city not found", domain.ErrInvalidAlertLocation)
+		}
+		if err != nil {
+			return err
+		}
+		loc.Name, loc.Lat, loc.Lon = city.CityName, city.Lat, city.Lon
+	} else if err := validateAlertCoordinates(loc); err != nil {
+		return err
+	}
+
+	return s.repo.CreateAlertLocation(ctx, loc)
+}
+
+// UpdateAlertLocation atualiza as categorias e, para locais com coordenadas
+// livres, o nome e a posição de um local de alerta.
+func (s *AlertLocationService) UpdateAlertLocation(ctx context.Context, loc *domain.AlertLocation) error {
+	existing, err := s.repo.GetAlertLocationByID(ctx, loc.UserID, loc.ID)
+	if err != nil {
+		return err
+	}
+
+	loc.FavoriteCityID = existing.FavoriteCityID
+	loc.IsCurrentLocation = existing.IsCurrentLocation
+	if loc.FavoriteCityID == nil {
+		if err := validateAlertCoordinates(loc); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.UpdateAlertLocation(ctx, loc)
+}
+
+// SetCurrentLocation atualiza a posição do local "localização atual" do
+// usuário, criando-o na primeira vez. As categorias existentes são mantidas.
+func (s *AlertLocationService) SetCurrentLocation(ctx context.Context, userID uuid.UUID, name string, lat, lon float64) (*domain.AlertLocation, error) {
+	loc, err := s.repo.GetCurrentLocation(ctx, userID)
+	if errors.Is(err, domain.ErrAlertLocationNotFound) {
+		loc = &domain.AlertLocation{
+			UserID:             userID,
+			IsCurrentLocation:  true,
+			IsEnabled:          true,
+			RainAlert:          true,
+			SevereWeatherAlert: true,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	loc.Name, loc.Lat, loc.Lon = name, lat, lon
+	if err := validateAlertCoordinates(loc); err != nil {
+		return nil, err
+	}
+
+	if loc.ID == uuid.Nil {
+		err = s.repo.CreateAlertLocation(ctx, loc)
+	} else {
+		err = s.repo.UpdateAlertLocation(ctx, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// DeleteAlertLocation remove um local de alerta do usuário.
+func (s *AlertLocationService) DeleteAlertLocation(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.DeleteAlertLocation(ctx, userID, id)
+}
+
+func validateAlertCoordinates(loc *domain.AlertLocation) error {
+	if !domain.ValidCoordinates(loc.Lat, loc.Lon) {
+		return fmt.Errorf("%w: lat must be within [-90, 90] and lon within [-180, 180]", domain.ErrInvalidAlertLocation)
+	}
+	return nil
+}
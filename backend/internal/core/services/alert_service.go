@@ -0,0 +1,192 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/clients/rainviewer"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+const (
+	// Antecedência máxima com que avisamos sobre chuva chegando.
+	rainAlertLeadMinutes = 30
+	// Intervalo mínimo entre dois alertas de chuva para o mesmo local.
+	rainAlertCooldown = 2 * time.Hour
+)
+
+// AlertService é o motor de alertas: avalia periodicamente os locais de alerta
+// dos usuários e dispara notificações.
+type AlertService struct {
+	alertLocationRepo   *database.AlertLocationRepository
+	nowcastService      *NowcastService
+	notificationService *NotificationService
+	dedup               *cache.AlertDedup
+}
+
+// NewAlertService cria uma nova instância de AlertService.
+func NewAlertService(
+	alertLocationRepo *database.AlertLocationRepository,
+	nowcastService *NowcastService,
+	notificationService *NotificationService,
+	dedup *cache.AlertDedup,
+) *AlertService {
+	return &AlertService{
+		alertLocationRepo:   alertLocationRepo,
+		nowcastService:      nowcastService,
+		notificationService: notificationService,
+		dedup:               dedup,
+	}
+}
+
+// RunRainAlerts avalia periodicamente o nowcast dos locais com alerta de chuva
+// e avisa quando a chuva deve começar nos próximos minutos, até que o contexto
+// seja cancelado.
+func (s *AlertService) RunRainAlerts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.evaluateRainAlerts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CountActiveAlertsByFavorite conta, por cidade favorita do usuário, os alertas
+// enviados que ainda estão em vigor.
+func (s *AlertService) CountActiveAlertsByFavorite(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	locations, err := s.alertLocationRepo.GetAlertLocationsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	var favorites []uuid.UUID
+	for _, loc := range locations {
+		if loc.FavoriteCityID == nil {
+			continue
+		}
+		keys = append(keys, rainAlertKey(loc))
+		favorites = append(favorites, *loc.FavoriteCityID)
+	}
+
+	counts := make(map[uuid.UUID]int)
+	if len(keys) == 0 {
+		return counts, nil
+	}
+	active, err := s.dedup.Active(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	for i, ok := range active {
+		if ok {
+			counts[favorites[i]]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *AlertService) evaluateRainAlerts(ctx context.Context) {
+	locations, err := s.alertLocationRepo.ListEnabledAlertLocations(ctx)
+	if err != nil {
+		log.Printf("failed to list alert locations: %v", err)
+		return
+	}
+
+	// Locais muito próximos (~1 km) compartilham o mesmo nowcast
+	groups := make(map[string][]*domain.AlertLocation)
+	for _, loc := range locations {
+		if !loc.AlertsCategory(domain.NotificationCategoryRain) {
+			continue
+		}
+		key := fmt.Sprintf("%.2f:%.2f", loc.Lat, loc.Lon)
+		groups[key] = append(groups[key], loc)
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	// Todos os grupos usam os mesmos frames da rodada; em ordem de posição no
+	// mapa, grupos vizinhos reaproveitam os tiles em cache do cliente de radar
+	radar, err := s.nowcastService.latestFrames(ctx)
+	if err != nil {
+		log.Printf("failed to get radar frames: %v", err)
+		return
+	}
+	ordered := slices.SortedFunc(maps.Values(groups), func(a, b []*domain.AlertLocation) int {
+		ax, ay := lonLatToPixel(a[0].Lat, a[0].Lon, nowcastZoom)
+		bx, by := lonLatToPixel(b[0].Lat, b[0].Lon, nowcastZoom)
+		if n := cmp.Compare(int(ay)/rainviewer.TileSize, int(by)/rainviewer.TileSize); n != 0 {
+			return n
+		}
+		return cmp.Compare(ax, bx)
+	})
+
+	for _, group := range ordered {
+		nowcast, err := s.nowcastService.nowcastAt(ctx, radar, group[0].Lat, group[0].Lon)
+		if err != nil {
+			log.Printf("failed to get nowcast for %.2f,%.2f: %v", group[0].Lat, group[0].Lon, err)
+			continue
+		}
+		if nowcast.RainStartsInMinutes == nil || *nowcast.RainStartsInMinutes > rainAlertLeadMinutes {
+			continue
+		}
+
+		for _, loc := range group {
+			s.sendRainAlert(ctx, loc, nowcast)
+		}
+	}
+}
+
+func (s *AlertService) sendRainAlert(ctx context.Context, loc *domain.AlertLocation, nowcast *domain.Nowcast) {
+	ok, err := s.dedup.Acquire(ctx, rainAlertKey(loc), rainAlertCooldown)
+	if err != nil {
+		log.Printf("failed to check rain alert cooldown for location %s: %v", loc.ID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	err = s.notificationService.Notify(ctx, &domain.Notification{
+		UserID:   loc.UserID,
+		Category: domain.NotificationCategoryRain,
+		Title:    "Chuva se aproximando",
+		Body:     fmt.Sprintf("Chuva começando em cerca de %d minutos em %s.", *nowcast.RainStartsInMinutes, loc.Name),
+		Severity: rainSeverity(nowcast.PeakPrecipitation),
+	})
+	if err != nil {
+		log.Printf("failed to send rain alert for location %s: %v", loc.ID, err)
+	}
+}
+
+// rainAlertKey identifica o alerta de chuva do local durante o intervalo mínimo
+// entre dois envios.
+func rainAlertKey(loc *domain.AlertLocation) string {
+	return "rain:" + loc.ID.String()
+}
+
+// rainSeverity classifica a intensidade máxima prevista (mm/h).
+func rainSeverity(peak float64) string {
+	switch {
+	case peak >= 50:
+		return domain.SeveritySevere
+	case peak >= 10:
+		return domain.SeverityWarning
+	default:
+		return domain.SeverityInfo
+	}
+}
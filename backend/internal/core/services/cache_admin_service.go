@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+)
+
+// CacheAdminService expõe à administração o conteúdo e as métricas do cache de
+// clima e o consumo da cota da API externa.
+type CacheAdminService struct {
+	weatherCache *cache.TieredCache
+	quota        *UpstreamQuotaService
+}
+
+// NewCacheAdminService cria uma nova instância de CacheAdminService.
+func NewCacheAdminService(weatherCache *cache.TieredCache, quota *UpstreamQuotaService) *CacheAdminService {
+	return &CacheAdminService{
+		weatherCache: weatherCache,
+		quota:        quota,
+	}
+}
+
+// InspectLocation retorna a entrada da célula em que a coordenada cai.
+func (s *CacheAdminService) InspectLocation(ctx context.Context, lat, lon float64) (*domain.CacheEntry, error) {
+	return s.InspectKey(ctx, s.weatherCache.Cell(lat, lon).Key)
+}
+
+// InspectKey retorna a entrada da chave de célula.
+func (s *CacheAdminService) InspectKey(ctx context.Context, key string) (*domain.CacheEntry, error) {
+	entry, err := s.weatherCache.Inspect(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, domain.ErrCacheEntryNotFound
+	}
+	return entry, nil
+}
+
+// Purge remove as entradas escolhidas e retorna quantas foram removidas.
+func (s *CacheAdminService) Purge(ctx context.Context, purge *domain.CachePurge) (int, error) {
+	if err := purge.Validate(); err != nil {
+		return 0, err
+	}
+	return s.weatherCache.Purge(ctx, purge)
+}
+
+// GetStats retorna as métricas do cache.
+func (s *CacheAdminService) GetStats(ctx context.Context) (*domain.CacheStats, error) {
+	return s.weatherCache.Stats(ctx)
+}
+
+// GetUpstreamQuota retorna o consumo atual da cota da API externa.
+func (s *CacheAdminService) GetUpstreamQuota(ctx context.Context) (*domain.UpstreamQuotaStatus, error) {
+	return s.quota.GetStatus(ctx)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+const (
+	// cacheWarmAhead é quanto antes do fim da validade flexível o aquecedor
+	// renova uma célula.
+	cacheWarmAhead = 5 * time.Minute
+	// cacheWarmerLockKey garante que só uma réplica aqueça o cache por rodada.
+	cacheWarmerLockKey = "cache-warmer"
+)
+
+// warmCandidate é uma célula do cache com os usuários que a acompanham.
+type warmCandidate struct {
+	cell         cache.Cell
+	users        map[uuid.UUID]struct{}
+	lastActiveAt time.Time
+}
+
+// CacheWarmingService renova proativamente as previsões das cidades favoritas
+// e dos locais de alerta, antes que expirem, dentro de um orçamento de
+// chamadas à API.
+type CacheWarmingService struct {
+	interestRepo    *database.LocationInterestRepository
+	weatherService  *WeatherService
+	lock            *cache.FetchLock
+	budgetPerMinute int
+}
+
+// NewCacheWarmingService cria uma nova instância de CacheWarmingService.
+// budgetPerMinute é o máximo de chamadas à API por minuto do aquecimento.
+func NewCacheWarmingService(interestRepo *database.LocationInterestRepository, weatherService *WeatherService, lock *cache.FetchLock, budgetPerMinute int) *CacheWarmingService {
+	return &CacheWarmingService{
+		interestRepo:    interestRepo,
+		weatherService:  weatherService,
+		lock:            lock,
+		budgetPerMinute: budgetPerMinute,
+	}
+}
+
+// RunWarmer aquece o cache a cada intervalo até o contexto ser cancelado.
+// Com orçamento zero, o aquecimento fica desligado.
+func (s *CacheWarmingService) RunWarmer(ctx context.Context, interval time.Duration) {
+	if s.budgetPerMinute <= 0 {
+		return
+	}
+	budget := max(1, int(float64(s.budgetPerMinute)*interval.Minutes()))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// O lock não é liberado: vale pela rodada inteira, e as outras réplicas
+		// pulam o intervalo em vez de gastar o orçamento de novo
+		if _, acquired, err := s.lock.Acquire(ctx, cacheWarmerLockKey, max(interval-time.Second, minJobLockTTL)); err != nil {
+			log.Printf("failed to acquire cache warmer lock: %v", err)
+		} else if acquired {
+			s.warm(ctx, budget)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *CacheWarmingService) warm(ctx context.Context, budget int) {
+	candidates, err := s.rank(ctx)
+	if err != nil {
+		log.Printf("failed to list locations to warm: %v", err)
+		return
+	}
+
+	// O orçamento conta chamadas à API, e cada busca gasta mais de uma
+	for _, c := range candidates {
+		if budget < openWeatherMapCallsPerFetch || ctx.Err() != nil {
+			return
+		}
+		calls, err := s.weatherService.Warm(ctx, c.cell, cacheWarmAhead)
+		if err != nil {
+			log.Printf("failed to warm weather cache for %s: %v", c.cell.Key, err)
+		}
+		budget -= calls
+	}
+}
+
+// rank agrupa os pontos acompanhados por célula e os ordena pelo número de
+// usuários e, no empate, pela atividade mais recente.
+func (s *CacheWarmingService) rank(ctx context.Context) ([]*warmCandidate, error) {
+	interests, err := s.interestRepo.ListLocationInterests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCell := make(map[string]*warmCandidate)
+	for _, li := range interests {
+		cell := s.weatherService.Cell(li.Lat, li.Lon)
+		c, ok := byCell[cell.Key]
+		if !ok {
+			c = &warmCandidate{cell: cell, users: make(map[uuid.UUID]struct{})}
+			byCell[cell.Key] = c
+		}
+		c.users[li.UserID] = struct{}{}
+		if li.LastActiveAt.After(c.lastActiveAt) {
+			c.lastActiveAt = li.LastActiveAt
+		}
+	}
+
+	candidates := make([]*warmCandidate, 0, len(byCell))
+	for _, c := range byCell {
+		candidates = append(candidates, c)
+	}
+	slices.SortFunc(candidates, func(a, b *warmCandidate) int {
+		if n := cmp.Compare(len(b.users), len(a.users)); n != 0 {
+			return n
+		}
+		return b.lastActiveAt.Compare(a.lastActiveAt)
+	})
+	return candidates, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// digestSchedulerLockKey garante que só uma réplica envie os resumos por rodada.
+const digestSchedulerLockKey = "digest-scheduler"
+
+// DigestService monta e agenda o resumo matinal com a previsão do dia para as
+// cidades favoritas de cada usuário.
+type DigestService struct {
+	settingsRepo        *database.NotificationSettingsRepository
+	favoriteCityRepo    *database.FavoriteCityRepository
+	weatherService      *WeatherService
+	notificationService *NotificationService
+	lock                *cache.FetchLock
+}
+
+// NewDigestService cria uma nova instância de DigestService.
+func NewDigestService(
+	settingsRepo *database.NotificationSettingsRepository,
+	favoriteCityRepo *database.FavoriteCityRepository,
+	weatherService *WeatherService,
+	notificationService *NotificationService,
+	lock *cache.FetchLock,
+) *DigestService {
+	return &DigestService{
+		settingsRepo:        settingsRepo,
+		favoriteCityRepo:    favoriteCityRepo,
+		weatherService:      weatherService,
+		notificationService: notificationService,
+		lock:                lock,
+	}
+}
+
+// RunScheduler verifica periodicamente quais usuários chegaram ao horário do
+// resumo no seu fuso e envia um único resumo por dia local, até que o contexto
+// seja cancelado. Cada rodada roda em uma só réplica.
+func (s *DigestService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runExclusive(ctx, s.lock, digestSchedulerLockKey, interval, func() {
+			s.sendDueDigests(ctx, time.Now())
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DigestService) sendDueDigests(ctx context.Context, now time.Time) {
+	list, err := s.settingsRepo.ListDigestEnabled(ctx)
+	if err != nil {
+		log.Printf("failed to list digest subscribers: %v", err)
+		return
+	}
+
+	for _, settings := range list {
+		local := now.In(settings.Location())
+		today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+		if settings.LastDigestSentOn != nil && !settings.LastDigestSentOn.Before(today) {
+			continue
+		}
+		digestAt, err := domain.ParseClock(settings.DigestTime)
+		if err != nil || local.Hour()*60+local.Minute() < digestAt {
+			continue
+		}
+
+		if err := s.sendDigest(ctx, settings); err != nil {
+			log.Printf("failed to send digest to user %s: %v", settings.UserID, err)
+			continue
+		}
+		if err := s.settingsRepo.MarkDigestSent(ctx, settings.UserID, today); err != nil {
+			log.Printf("failed to record digest for user %s: %v", settings.UserID, err)
+		}
+	}
+}
+
+func (s *DigestService) sendDigest(ctx context.Context, settings *domain.NotificationSettings) error {
+	cities, err := s.favoriteCityRepo.GetFavoriteCitiesByUserID(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	if len(cities) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(cities))
+	for _, city := range cities {
+		// O resumo aceita previsão em cache e não disputa a cota com quem está no app
+		weatherData, err := s.weatherService.GetWeatherData(ctx, city.Lat, city.Lon, false)
+		if err != nil || len(weatherData.Daily) == 0 {
+			lines = append(lines, city.CityName+": previsão indisponível")
+			continue
+		}
+		lines = append(lines, formatDigestLine(city.CityName, weatherData.Daily[0]))
+	}
+
+	return s.notificationService.Notify(ctx, &domain.Notification{
+		UserID:   settings.UserID,
+		Category: domain.NotificationCategoryDigest,
+		Title:    "Resumo do dia",
+		Body:     strings.Join(lines, "\n"),
+		Severity: domain.SeverityInfo,
+	})
+}
+
+func formatDigestLine(cityName string, day domain.DailyForecast) string {
+	return fmt.Sprintf("%s: %s, %.0f°–%.0f°C, %d%% de chance de chuva",
+		cityName, day.Summary, day.Temp.Min, day.Temp.Max, int(math.Round(day.Pop*100)))
+}
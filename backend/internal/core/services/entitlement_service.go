@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// EntitlementService resolve o plano de cada usuário a partir da assinatura e
+// aplica os direitos do catálogo de planos.
+type EntitlementService struct {
+	subscriptionRepo *database.SubscriptionRepository
+	usageCounter     *cache.UsageCounter
+}
+
+// NewEntitlementService cria uma nova instância de EntitlementService.
+func NewEntitlementService(subscriptionRepo *database.SubscriptionRepository, usageCounter *cache.UsageCounter) *EntitlementService {
+	return &EntitlementService{
+		subscriptionRepo: subscriptionRepo,
+		usageCounter:     usageCounter,
+	}
+}
+
+// GetPlan retorna o plano vigente do usuário. Sem assinatura, ou com ela em um
+// estado que não dá direito ao plano, o usuário fica no plano gratuito.
+func (s *EntitlementService) GetPlan(ctx context.Context, userID uuid.UUID) (domain.Plan, error) {
+	sub, err := s.subscriptionRepo.GetSubscriptionByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.PlanFor(domain.PlanFree), nil
+	}
+	if err != nil {
+		return domain.Plan{}, err
+	}
+
+	return domain.PlanFor(sub.EffectivePlan(time.Now())), nil
+}
+
+// CheckLimit verifica se o usuário pode passar de current para current+1 no
+// direito informado.
+func (s *EntitlementService) CheckLimit(ctx context.Context, userID uuid.UUID, feature string, current int) error {
+	plan, err := s.GetPlan(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if limit := plan.Limit(feature); current >= limit {
+		return planLimitError(plan, feature)
+	}
+	return nil
+}
+
+// RequireFeature verifica se o plano do usuário libera o recurso.
+func (s *EntitlementService) RequireFeature(ctx context.Context, userID uuid.UUID, feature string) error {
+	return s.CheckLimit(ctx, userID, feature, 0)
+}
+
+// ConsumeAPIQuota contabiliza uma requisição na cota diária (UTC) do usuário e
+// falha quando ela se esgota.
+func (s *EntitlementService) ConsumeAPIQuota(ctx context.Context, userID uuid.UUID) error {
+	plan, err := s.GetPlan(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	key := "api:" + userID.String() + ":" + time.Now().UTC().Format("2006-01-02")
+	used, err := s.usageCounter.Increment(ctx, key, 48*time.Hour)
+	if err != nil {
+		return err
+	}
+	if used > int64(plan.DailyAPIQuota) {
+		return planLimitError(plan, domain.FeatureDailyAPIQuota)
+	}
+	return nil
+}
+
+func planLimitError(plan domain.Plan, feature string) error {
+	return &domain.PlanLimitError{
+		Feature:     feature,
+		Limit:       plan.Limit(feature),
+		CurrentPlan: plan.Level,
+		UpgradeTo:   plan.UpgradeFor(feature),
+	}
+}
@@ -2,35 +2,151 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/platform/database"
 )
 
 // FavoriteCityService é um serviço para cidades favoritas.
 type FavoriteCityService struct {
-	repo *database.FavoriteCityRepository
+	repo           *database.FavoriteCityRepository
+	groupRepo      *database.FavoriteGroupRepository
+	entitlements   *EntitlementService
+	weatherService *WeatherService
+	alertService   *AlertService
 }
 
 // NewFavoriteCityService cria uma nova instância de FavoriteCityService.
-func NewFavoriteCityService(repo *database.FavoriteCityRepository) *FavoriteCityService {
+func NewFavoriteCityService(repo *database.FavoriteCityRepository, groupRepo *database.FavoriteGroupRepository, entitlements *EntitlementService, weatherService *WeatherService, alertService *AlertService) *FavoriteCityService {
 	return &FavoriteCityService{
-		repo: repo,
+		repo:           repo,
+		groupRepo:      groupRepo,
+		entitlements:   entitlements,
+		weatherService: weatherService,
+		alertService:   alertService,
 	}
 }
 
-// CreateFavoriteCity cria uma nova cidade favorita.
+// CreateFavoriteCity cria uma nova cidade favorita, respeitando o limite do plano.
 func (s *FavoriteCityService) CreateFavoriteCity(ctx context.Context, city *domain.FavoriteCity) error {
+	if err := s.ValidateFavoriteCity(ctx, city); err != nil {
+		return err
+	}
+
+	if err := s.CheckFavoriteCityLimit(ctx, city.UserID); err != nil {
+		return err
+	}
 	return s.repo.CreateFavoriteCity(ctx, city)
 }
 
-// GetFavoriteCitiesByUserID obtém todas as cidades favoritas de um usuário.
-func (s *FavoriteCityService) GetFavoriteCitiesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.FavoriteCity, error) {
-	return s.repo.GetFavoriteCitiesByUserID(ctx, userID)
+// CheckFavoriteCityLimit verifica se o plano do usuário permite mais uma
+// cidade favorita.
+func (s *FavoriteCityService) CheckFavoriteCityLimit(ctx context.Context, userID uuid.UUID) error {
+	count, err := s.repo.CountFavoriteCitiesByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.entitlements.CheckLimit(ctx, userID, domain.FeatureMaxFavorites, count)
+}
+
+// GetFavoriteCitiesByUserID obtém as cidades favoritas de um usuário que
+// passam pelo filtro. Filtrar por um grupo que não é do usuário retorna
+// domain.ErrFavoriteGroupNotFound.
+func (s *FavoriteCityService) GetFavoriteCitiesByUserID(ctx context.Context, userID uuid.UUID, filter domain.FavoriteCityFilter) ([]*domain.FavoriteCity, error) {
+	if filter.GroupID != nil {
+		if _, err := s.groupRepo.GetFavoriteGroupByID(ctx, userID, *filter.GroupID); err != nil {
+			return nil, err
+		}
+	}
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	return s.repo.ListFavoriteCities(ctx, userID, filter)
+}
+
+// GetFavoriteCitiesWithCurrent obtém as cidades favoritas do usuário com as
+// condições atuais de cada uma. As previsões vêm do cache, e as que faltam são
+// buscadas em paralelo; uma falha aparece só no item afetado.
+func (s *FavoriteCityService) GetFavoriteCitiesWithCurrent(ctx context.Context, userID uuid.UUID, filter domain.FavoriteCityFilter) ([]*domain.FavoriteCity, error) {
+	cities, err := s.GetFavoriteCitiesByUserID(ctx, userID, filter)
+	if err != nil || len(cities) == 0 {
+		return cities, err
+	}
+
+	plan, err := s.entitlements.GetPlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := s.alertService.CountActiveAlertsByFavorite(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]domain.Coordinates, len(cities))
+	for i, city := range cities {
+		points[i] = domain.Coordinates{Lat: city.Lat, Lon: city.Lon}
+	}
+	results := s.weatherService.GetWeatherBatch(ctx, points, plan.Level != domain.PlanFree)
+
+	for i, city := range cities {
+		if results[i].Weather != nil {
+			city.Current = domain.NewCurrentConditions(results[i].Weather)
+		} else {
+			city.Current = &domain.CurrentConditions{Error: results[i].Error}
+		}
+		city.Current.AlertsCount = alerts[city.ID]
+	}
+	return cities, nil
+}
+
+// UpdateFavoriteCity altera os campos presentes no patch de uma cidade
+// favorita do usuário e retorna a cidade atualizada.
+func (s *FavoriteCityService) UpdateFavoriteCity(ctx context.Context, userID, id uuid.UUID, patch *domain.FavoriteCityPatch) (*domain.FavoriteCity, error) {
+	city, err := s.repo.GetFavoriteCityByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && city.UserID != userID) {
+		return nil, domain.ErrFavoriteCityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patch.Apply(city)
+	if err := s.ValidateFavoriteCity(ctx, city); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateFavoriteCity(ctx, city); err != nil {
+		return nil, err
+	}
+	return city, nil
+}
+
+// ReorderFavoriteCities define a ordem das cidades favoritas do usuário. ids
+// deve listar todas elas, cada uma uma única vez.
+func (s *FavoriteCityService) ReorderFavoriteCities(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	return s.repo.ReorderFavoriteCities(ctx, userID, ids)
 }
 
 // DeleteFavoriteCity remove uma cidade favorita.
 func (s *FavoriteCityService) DeleteFavoriteCity(ctx context.Context, id uuid.UUID) error {
 	return s.repo.DeleteFavoriteCity(ctx, id)
 }
+
+// ValidateFavoriteCity normaliza e valida a cidade e garante que o grupo dela,
+// se houver, é do mesmo usuário.
+func (s *FavoriteCityService) ValidateFavoriteCity(ctx context.Context, city *domain.FavoriteCity) error {
+	city.Normalize()
+	if err := city.Validate(); err != nil {
+		return err
+	}
+	if city.GroupID == nil {
+		return nil
+	}
+	_, err := s.groupRepo.GetFavoriteGroupByID(ctx, city.UserID, *city.GroupID)
+	if errors.Is(err, domain.ErrFavoriteGroupNotFound) {
+		return fmt.Errorf("%w: favorite group not found", domain.ErrInvalidFavoriteCity)
+	}
+	return err
+}
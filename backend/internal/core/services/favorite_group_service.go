@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// FavoriteGroupService é um serviço para os grupos de cidades favoritas.
+type FavoriteGroupService struct {
+	repo *database.FavoriteGroupRepository
+}
+
+// NewFavoriteGroupService cria uma nova instância de FavoriteGroupService.
+func NewFavoriteGroupService(repo *database.FavoriteGroupRepository) *FavoriteGroupService {
+	return &FavoriteGroupService{
+		repo: repo,
+	}
+}
+
+// GetFavoriteGroupsByUserID obtém os grupos do usuário com a contagem de
+// favoritas de cada um.
+func (s *FavoriteGroupService) GetFavoriteGroupsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.FavoriteGroup, error) {
+	return s.repo.GetFavoriteGroupsByUserID(ctx, userID)
+}
+
+// CreateFavoriteGroup cria um grupo com nome único para o usuário.
+func (s *FavoriteGroupService) CreateFavoriteGroup(ctx context.Context, group *domain.FavoriteGroup) error {
+	group.Normalize()
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateFavoriteGroup(ctx, group)
+}
+
+// RenameFavoriteGroup altera o nome de um grupo do usuário e retorna o grupo
+// atualizado.
+func (s *FavoriteGroupService) RenameFavoriteGroup(ctx context.Context, userID, id uuid.UUID, name string) (*domain.FavoriteGroup, error) {
+	group, err := s.repo.GetFavoriteGroupByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = name
+	group.Normalize()
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenameFavoriteGroup(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteFavoriteGroup remove um grupo do usuário sem remover as favoritas dele.
+func (s *FavoriteGroupService) DeleteFavoriteGroup(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.DeleteFavoriteGroup(ctx, userID, id)
+}
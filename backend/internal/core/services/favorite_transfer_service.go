@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/clients/openweathermap"
+	"github.com/weatherpro/backend/internal/platform/geoformat"
+	"golang.org/x/sync/errgroup"
+)
+
+// favoriteImportGeocodeParallelism limita as geocodificações simultâneas de
+// uma importação.
+const favoriteImportGeocodeParallelism = 4
+
+// FavoriteTransferService exporta e importa as cidades favoritas em arquivos
+// GeoJSON, KML e CSV.
+type FavoriteTransferService struct {
+	favoriteCityService *FavoriteCityService
+	weatherClient       *openweathermap.Client
+	quota               *UpstreamQuotaService
+}
+
+// NewFavoriteTransferService cria uma nova instância de FavoriteTransferService.
+func NewFavoriteTransferService(favoriteCityService *FavoriteCityService, weatherClient *openweathermap.Client, quota *UpstreamQuotaService) *FavoriteTransferService {
+	return &FavoriteTransferService{
+		favoriteCityService: favoriteCityService,
+		weatherClient:       weatherClient,
+		quota:               quota,
+	}
+}
+
+// ExportFavoriteCities grava em w as cidades favoritas do usuário que passam
+// pelo filtro, no formato informado.
+func (s *FavoriteTransferService) ExportFavoriteCities(ctx context.Context, userID uuid.UUID, format string, filter domain.FavoriteCityFilter, w io.Writer) error {
+	if !domain.ValidFavoriteFormat(format) {
+		return fmt.Errorf("%w: %q", domain.ErrUnsupportedFavoriteFormat, format)
+	}
+	cities, err := s.favoriteCityService.GetFavoriteCitiesByUserID(ctx, userID, filter)
+	if err != nil {
+		return err
+	}
+	return geoformat.Encode(format, w, cities)
+}
+
+// ImportFavoriteCities cria as cidades do arquivo como favoritas do usuário,
+// opcionalmente dentro de um grupo. Locais sem país (ou sem nome) são
+// geocodificados; os que já são favoritos, no arquivo ou na conta, são
+// ignorados. O relatório traz o resultado de cada local.
+func (s *FavoriteTransferService) ImportFavoriteCities(ctx context.Context, userID uuid.UUID, format string, groupID *uuid.UUID, r io.Reader) (*domain.FavoriteImportReport, error) {
+	rows, err := geoformat.Decode(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows) > domain.MaxFavoriteImportRows {
+		return nil, fmt.Errorf("%w: between 1 and %d locations are required", domain.ErrInvalidFavoriteImport, domain.MaxFavoriteImportRows)
+	}
+
+	existing, err := s.favoriteCityService.GetFavoriteCitiesByUserID(ctx, userID, domain.FavoriteCityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, city := range existing {
+		seen[domain.FavoriteLocationKey(city.Lat, city.Lon)] = true
+	}
+
+	// Locais ilegíveis ou já favoritos são resolvidos antes da geocodificação,
+	// que gasta cota da API
+	report := &domain.FavoriteImportReport{Rows: make([]*domain.FavoriteImportRow, len(rows))}
+	pending := make([]bool, len(rows))
+	for i, row := range rows {
+		result := &domain.FavoriteImportRow{Row: row.Row}
+		report.Rows[i] = result
+		if row.Err != nil {
+			result.Status = domain.FavoriteImportFailed
+			result.Error = row.Err.Error()
+			continue
+		}
+		row.City.UserID = userID
+		row.City.GroupID = groupID
+		row.City.Normalize()
+		result.CityName = row.City.CityName
+		if seen[domain.FavoriteLocationKey(row.City.Lat, row.City.Lon)] {
+			result.Status = domain.FavoriteImportDuplicate
+			continue
+		}
+		pending[i] = true
+	}
+
+	geocodeErrs := s.geocode(ctx, rows, pending)
+
+	for i, row := range rows {
+		if !pending[i] {
+			continue
+		}
+		result := report.Rows[i]
+		result.CityName = row.City.CityName
+		if geocodeErrs[i] != nil {
+			result.Status = domain.FavoriteImportFailed
+			result.Error = geocodeErrs[i].Error()
+			continue
+		}
+
+		// Repetições dentro do próprio arquivo
+		key := domain.FavoriteLocationKey(row.City.Lat, row.City.Lon)
+		if seen[key] {
+			result.Status = domain.FavoriteImportDuplicate
+			continue
+		}
+
+		err := s.favoriteCityService.CreateFavoriteCity(ctx, row.City)
+		switch {
+		case err == nil:
+			result.Status = domain.FavoriteImportImported
+			result.FavoriteID = &row.City.ID
+			seen[key] = true
+		case errors.Is(err, domain.ErrFavoriteCityExists):
+			result.Status = domain.FavoriteImportDuplicate
+		case errors.Is(err, domain.ErrInvalidFavoriteCity), errors.Is(err, domain.ErrPlanLimit):
+			result.Status = domain.FavoriteImportFailed
+			result.Error = err.Error()
+		default:
+			return nil, err
+		}
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case domain.FavoriteImportImported:
+			report.Imported++
+		case domain.FavoriteImportDuplicate:
+			report.Duplicates++
+		default:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// geocode preenche o país e, se faltar, o nome dos locais pendentes pela
+// geocodificação reversa. Retorna, por linha, o motivo de o local continuar
+// sem país; sem nome, a validação o rejeita depois. Perto do limite da API, a
+// geocodificação é suspensa para sobrar cota às previsões.
+func (s *FavoriteTransferService) geocode(ctx context.Context, rows []geoformat.Row, pending []bool) []error {
+	errs := make([]error, len(rows))
+	unavailable := domain.QuotaLevelAtLeast(s.quota.Level(), domain.QuotaLevelCritical)
+
+	var g errgroup.Group
+	g.SetLimit(favoriteImportGeocodeParallelism)
+	for i, row := range rows {
+		if !pending[i] || (row.City.CountryCode != "" && row.City.CityName != "") {
+			continue
+		}
+		// Coordenadas inválidas são rejeitadas na validação, sem gastar cota
+		if (domain.Coordinates{Lat: row.City.Lat, Lon: row.City.Lon}).Validate() != nil {
+			continue
+		}
+		if unavailable {
+			if row.City.CountryCode == "" {
+				errs[i] = fmt.Errorf("%w: country_code is missing and geocoding is temporarily unavailable", domain.ErrInvalidFavoriteCity)
+			}
+			continue
+		}
+
+		g.Go(func() error {
+			s.quota.Record(ctx, 1)
+			place, err := s.weatherClient.ReverseGeocode(row.City.Lat, row.City.Lon)
+			if err != nil {
+				log.Printf("failed to geocode imported favorite at %f,%f: %v", row.City.Lat, row.City.Lon, err)
+				if row.City.CountryCode == "" {
+					errs[i] = fmt.Errorf("%w: country_code is missing and could not be geocoded", domain.ErrInvalidFavoriteCity)
+				}
+				return nil
+			}
+			if row.City.CityName == "" {
+				row.City.CityName = place.Name
+			}
+			if row.City.CountryCode == "" {
+				row.City.CountryCode = place.CountryCode
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return errs
+}
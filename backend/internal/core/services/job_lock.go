@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/weatherpro/backend/internal/platform/cache"
+)
+
+// minJobLockTTL é a menor validade do lock de uma rodada. Um SetNX com
+// validade zero ou negativa não expira, e o job pararia em todas as réplicas.
+const minJobLockTTL = time.Second
+
+// runExclusive executa fn só na réplica que obtiver o lock da rodada; as
+// demais pulam o intervalo. O lock é liberado ao fim e expira sozinho em ttl
+// se a réplica cair no meio da rodada.
+func runExclusive(ctx context.Context, lock *cache.FetchLock, key string, ttl time.Duration, fn func()) {
+	release, acquired, err := lock.Acquire(ctx, key, max(ttl, minJobLockTTL))
+	if err != nil {
+		log.Printf("failed to acquire %s lock: %v", key, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer release()
+
+	fn()
+}
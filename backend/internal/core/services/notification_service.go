@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+	pendingDeliveryBatchSize    = 100
+	// deliveryClaimLease é por quanto tempo uma notificação reservada para envio
+	// fica fora do alcance dos outros envios; passado esse tempo, considera-se
+	// que a réplica que a reservou caiu.
+	deliveryClaimLease = 5 * time.Minute
+	// deliveryDispatcherLockKey garante que só uma réplica envie as
+	// notificações adiadas por rodada.
+	deliveryDispatcherLockKey = "notification-delivery-dispatcher"
+)
+
+// NotificationSender entrega notificações ao dispositivo do usuário.
+type NotificationSender interface {
+	Send(ctx context.Context, n *domain.Notification) error
+}
+
+// EventPublisher publica eventos para integrações externas do usuário.
+type EventPublisher interface {
+	Publish(ctx context.Context, userID uuid.UUID, eventType string, data any)
+}
+
+// NotificationService é um serviço para o envio e o histórico de notificações.
+type NotificationService struct {
+	repo         *database.NotificationRepository
+	settingsRepo *database.NotificationSettingsRepository
+	sender       NotificationSender
+	publisher    EventPublisher
+	lock         *cache.FetchLock
+}
+
+// NewNotificationService cria uma nova instância de NotificationService.
+func NewNotificationService(
+	repo *database.NotificationRepository,
+	settingsRepo *database.NotificationSettingsRepository,
+	sender NotificationSender,
+	publisher EventPublisher,
+	lock *cache.FetchLock,
+) *NotificationService {
+	return &NotificationService{
+		repo:         repo,
+		settingsRepo: settingsRepo,
+		sender:       sender,
+		publisher:    publisher,
+		lock:         lock,
+	}
+}
+
+// Notify registra a notificação na caixa de entrada do usuário e a envia ao
+// dispositivo respeitando as configurações dele. Notificações que caem no
+// horário silencioso ficam pendentes até o fim da janela; os webhooks do
+// usuário recebem o evento imediatamente.
+func (s *NotificationService) Notify(ctx context.Context, n *domain.Notification) error {
+	if n.Severity == "" {
+		n.Severity = domain.SeverityInfo
+	}
+
+	settings, err := s.settingsRepo.GetNotificationSettingsByUserID(ctx, n.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	now := time.Now()
+	deliverAt, deliver := now, true
+	if settings != nil {
+		deliverAt, deliver = settings.DeliveryTime(n, now)
+	}
+	if deliver {
+		n.DeliverAt = &deliverAt
+	}
+
+	if err := s.repo.CreateNotification(ctx, n); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, n.UserID, domain.NotificationEventType(n.Category), n)
+
+	// O despachante pode pegar a notificação entre a gravação e o envio; a
+	// reserva garante que só um dos dois a envie
+	if deliver && !deliverAt.After(now) {
+		claimed, err := s.repo.ClaimDelivery(ctx, n.ID, now, deliveryClaimLease)
+		if err != nil {
+			log.Printf("failed to claim notification %s for delivery: %v", n.ID, err)
+		} else if claimed {
+			s.deliver(ctx, n)
+		}
+	}
+	return nil
+}
+
+// RunDeliveryDispatcher envia periodicamente as notificações adiadas cujo
+// horário de envio já chegou, até que o contexto seja cancelado. Cada rodada
+// roda em uma só réplica, e cada notificação é reservada antes do envio para
+// não ser enviada também pelo envio imediato de Notify.
+func (s *NotificationService) RunDeliveryDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runExclusive(ctx, s.lock, deliveryDispatcherLockKey, interval, func() {
+			pending, err := s.repo.ClaimPendingDeliveries(ctx, time.Now(), deliveryClaimLease, pendingDeliveryBatchSize)
+			if err != nil {
+				log.Printf("failed to claim pending notifications: %v", err)
+			}
+			for _, n := range pending {
+				s.deliver(ctx, n)
+			}
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver envia uma notificação já reservada e a marca como entregue. Falhas
+// são apenas registradas; a reserva é desfeita e a notificação continua
+// pendente para o despachante tentar novamente.
+func (s *NotificationService) deliver(ctx context.Context, n *domain.Notification) {
+	if err := s.sender.Send(ctx, n); err != nil {
+		log.Printf("failed to deliver notification %s: %v", n.ID, err)
+		if err := s.repo.ReleaseDeliveryClaim(context.WithoutCancel(ctx), n.ID); err != nil {
+			log.Printf("failed to release notification %s: %v", n.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	if err := s.repo.MarkDelivered(ctx, n.ID, now); err != nil {
+		log.Printf("failed to mark notification %s as delivered: %v", n.ID, err)
+		return
+	}
+	n.DeliveredAt = &now
+}
+
+// ListNotifications obtém uma página do histórico de notificações de um usuário.
+// O cursor vazio retorna a primeira página.
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*domain.NotificationPage, error) {
+	if limit <= 0 {
+		limit = defaultNotificationPageSize
+	}
+	if limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+
+	var before *time.Time
+	var beforeID uuid.UUID
+	if cursor != "" {
+		t, id, err := decodeNotificationCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		before, beforeID = &t, id
+	}
+
+	// Busca um item a mais para saber se existe uma próxima página
+	items, err := s.repo.ListNotificationsByUserID(ctx, userID, before, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnreadByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.NotificationPage{
+		Items:       items,
+		UnreadCount: unread,
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeNotificationCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// MarkAsRead marca uma notificação do usuário como lida.
+func (s *NotificationService) MarkAsRead(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.MarkAsRead(ctx, userID, id)
+}
+
+// MarkAllAsRead marca todas as notificações do usuário como lidas.
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.repo.MarkAllAsRead(ctx, userID)
+	return err
+}
+
+// RunRetentionCleanup remove periodicamente as notificações mais antigas que o
+// período de retenção, até que o contexto seja cancelado.
+func (s *NotificationService) RunRetentionCleanup(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.repo.DeleteOlderThan(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("notification retention cleanup failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("notification retention cleanup removed %d notifications", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func encodeNotificationCursor(t time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(t.UnixNano(), 10) + "_" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeNotificationCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	nanos, idStr, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return time.Time{}, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	return time.Unix(0, n), id, nil
+}
@@ -27,5 +27,29 @@ func (s *NotificationSettingsService) GetNotificationSettingsByUserID(ctx contex
 
 // UpdateNotificationSettings atualiza as configurações de notificação de um usuário.
 func (s *NotificationSettingsService) UpdateNotificationSettings(ctx context.Context, settings *domain.NotificationSettings) error {
+	applyNotificationSettingsDefaults(settings)
+	if err := settings.Validate(); err != nil {
+		return err
+	}
 	return s.repo.UpdateNotificationSettings(ctx, settings)
 }
+
+// applyNotificationSettingsDefaults preenche os campos omitidos pelo cliente
+// com os mesmos valores padrão das colunas no banco.
+func applyNotificationSettingsDefaults(settings *domain.NotificationSettings) {
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+	if settings.QuietHoursStart == "" {
+		settings.QuietHoursStart = "22:00"
+	}
+	if settings.QuietHoursEnd == "" {
+		settings.QuietHoursEnd = "07:00"
+	}
+	if settings.DigestTime == "" {
+		settings.DigestTime = "07:00"
+	}
+	if settings.CategoryOverrides == nil {
+		settings.CategoryOverrides = map[string]domain.CategoryOverride{}
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/clients/rainviewer"
+)
+
+const (
+	// Zoom 7 dá cerca de 1,2 km por pixel no equador.
+	nowcastZoom = 7
+	// Quantidade de frames usados para estimar o movimento (~10 min entre eles).
+	nowcastFrames = 6
+	// Raio, em pixels, da janela analisada ao redor do ponto.
+	nowcastWindowRadius = 48
+	// Deslocamento máximo procurado entre dois frames, em pixels.
+	nowcastMaxShift = 8
+	// Horizonte da previsão, em minutos.
+	nowcastHorizon = 60
+
+	// Latitude máxima da projeção Web Mercator; além dela não há tiles.
+	maxMercatorLat = 85.05112878
+
+	// RainThreshold é a intensidade mínima (mm/h) considerada chuva.
+	RainThreshold = 0.1
+)
+
+// ErrNoRadarData indica que não há frames de radar disponíveis.
+var ErrNoRadarData = errors.New("no radar data available")
+
+// NowcastService produz previsões de precipitação de curtíssimo prazo a partir
+// de frames de radar, estimando o deslocamento da chuva por correlação cruzada.
+type NowcastService struct {
+	radarClient *rainviewer.Client
+}
+
+// NewNowcastService cria uma nova instância de NowcastService.
+func NewNowcastService(radarClient *rainviewer.Client) *NowcastService {
+	return &NowcastService{
+		radarClient: radarClient,
+	}
+}
+
+// radarGrid é uma janela quadrada de intensidades de chuva (mm/h) centrada no ponto.
+type radarGrid struct {
+	size   int
+	values []float64
+}
+
+func (g *radarGrid) at(x, y int) float64 {
+	if x < 0 || y < 0 || x >= g.size || y >= g.size {
+		return 0
+	}
+	return g.values[y*g.size+x]
+}
+
+// radarFrames são os frames de radar usados em uma rodada de previsões.
+type radarFrames struct {
+	host   string
+	frames []rainviewer.Frame
+}
+
+// GetNowcast obtém a previsão minuto a minuto da próxima hora para o ponto.
+func (s *NowcastService) GetNowcast(ctx context.Context, lat, lon float64) (*domain.Nowcast, error) {
+	radar, err := s.latestFrames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.nowcastAt(ctx, radar, lat, lon)
+}
+
+// latestFrames obtém os frames de radar mais recentes. Quem calcula previsões
+// para vários pontos busca a lista uma vez e a reaproveita em nowcastAt.
+func (s *NowcastService) latestFrames(ctx context.Context) (*radarFrames, error) {
+	host, frames, err := s.radarClient.GetRadarFrames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, ErrNoRadarData
+	}
+	if len(frames) > nowcastFrames {
+		frames = frames[len(frames)-nowcastFrames:]
+	}
+	return &radarFrames{host: host, frames: frames}, nil
+}
+
+// nowcastAt calcula a previsão para o ponto a partir dos frames informados.
+func (s *NowcastService) nowcastAt(ctx context.Context, radar *radarFrames, lat, lon float64) (*domain.Nowcast, error) {
+	frames := radar.frames
+	px, py := lonLatToPixel(lat, lon, nowcastZoom)
+	grids := make([]*radarGrid, len(frames))
+	for i, frame := range frames {
+		var err error
+		grids[i], err = s.loadGrid(ctx, radar.host, frame, px, py)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Velocidade média em pixels por minuto entre pares consecutivos de frames
+	var vx, vy float64
+	pairs := 0
+	for i := 1; i < len(grids); i++ {
+		minutes := float64(frames[i].Time-frames[i-1].Time) / 60
+		if minutes <= 0 {
+			continue
+		}
+		dx, dy, ok := estimateMotion(grids[i-1], grids[i])
+		if !ok {
+			continue
+		}
+		vx += float64(dx) / minutes
+		vy += float64(dy) / minutes
+		pairs++
+	}
+	if pairs > 0 {
+		vx /= float64(pairs)
+		vy /= float64(pairs)
+	}
+
+	latest := grids[len(grids)-1]
+	radarTime := frames[len(frames)-1].Time
+	now := time.Now().Truncate(time.Minute)
+	age := now.Sub(time.Unix(radarTime, 0)).Minutes()
+	if age < 0 {
+		age = 0
+	}
+
+	nowcast := &domain.Nowcast{
+		Lat:       lat,
+		Lon:       lon,
+		RadarTime: radarTime,
+		Minutely:  make([]domain.MinutelyForecast, 0, nowcastHorizon+1),
+	}
+
+	// Extrapolação semi-lagrangiana: a chuva prevista para o ponto daqui a
+	// "lead" minutos é a que está hoje a montante, na direção do movimento.
+	center := float64(nowcastWindowRadius)
+	for m := 0; m <= nowcastHorizon; m++ {
+		lead := float64(m) + age
+		precipitation := neighborhoodMean(latest, center-vx*lead, center-vy*lead)
+		nowcast.Minutely = append(nowcast.Minutely, domain.MinutelyForecast{
+			Dt:            now.Add(time.Duration(m) * time.Minute).Unix(),
+			Precipitation: math.Round(precipitation*100) / 100,
+		})
+		nowcast.PeakPrecipitation = math.Max(nowcast.PeakPrecipitation, nowcast.Minutely[m].Precipitation)
+	}
+
+	raining := nowcast.Minutely[0].Precipitation >= RainThreshold
+	for m := 1; m < len(nowcast.Minutely); m++ {
+		wet := nowcast.Minutely[m].Precipitation >= RainThreshold
+		if wet != raining {
+			minutes := m
+			if raining {
+				nowcast.RainEndsInMinutes = &minutes
+			} else {
+				nowcast.RainStartsInMinutes = &minutes
+			}
+			break
+		}
+	}
+
+	metersPerPixel := 156543.03392 * math.Cos(lat*math.Pi/180) / math.Exp2(nowcastZoom)
+	nowcast.MotionSpeedKmh = math.Round(math.Hypot(vx, vy)*metersPerPixel*60/1000*10) / 10
+	if vx != 0 || vy != 0 {
+		// No plano do mapa o eixo y cresce para o sul
+		direction := math.Atan2(vx, -vy) * 180 / math.Pi
+		nowcast.MotionDirectionDeg = math.Round(math.Mod(direction+360, 360))
+	}
+
+	return nowcast, nil
+}
+
+// loadGrid monta a janela ao redor do pixel global (px, py), buscando os tiles
+// que ela cobre e convertendo refletividade em intensidade de chuva.
+func (s *NowcastService) loadGrid(ctx context.Context, host string, frame rainviewer.Frame, px, py float64) (*radarGrid, error) {
+	size := 2*nowcastWindowRadius + 1
+	originX := int(math.Floor(px)) - nowcastWindowRadius
+	originY := int(math.Floor(py)) - nowcastWindowRadius
+	tilesPerAxis := 1 << nowcastZoom
+	worldSize := tilesPerAxis * rainviewer.TileSize
+
+	grid := &radarGrid{size: size, values: make([]float64, size*size)}
+	tiles := make(map[[2]int][]float32)
+
+	for gy := 0; gy < size; gy++ {
+		wy := originY + gy
+		if wy < 0 || wy >= worldSize {
+			continue
+		}
+		for gx := 0; gx < size; gx++ {
+			// A longitude dá a volta no antimeridiano
+			wx := ((originX+gx)%worldSize + worldSize) % worldSize
+			tx, ty := wx/rainviewer.TileSize, wy/rainviewer.TileSize
+
+			tile, ok := tiles[[2]int{tx, ty}]
+			if !ok {
+				var err error
+				tile, err = s.radarClient.GetReflectivityTile(ctx, host, frame, nowcastZoom, tx, ty)
+				if err != nil {
+					return nil, err
+				}
+				tiles[[2]int{tx, ty}] = tile
+			}
+
+			dbz := float64(tile[(wy%rainviewer.TileSize)*rainviewer.TileSize+wx%rainviewer.TileSize])
+			grid.values[gy*size+gx] = rainRate(dbz)
+		}
+	}
+
+	return grid, nil
+}
+
+// estimateMotion procura o deslocamento (dx, dy) que melhor sobrepõe o frame
+// anterior ao seguinte, maximizando a correlação cruzada normalizada. Retorna
+// false quando não há chuva suficiente para estimar o movimento.
+func estimateMotion(prev, next *radarGrid) (int, int, bool) {
+	bestDX, bestDY := 0, 0
+	bestScore := 0.0
+	found := false
+
+	for dy := -nowcastMaxShift; dy <= nowcastMaxShift; dy++ {
+		for dx := -nowcastMaxShift; dx <= nowcastMaxShift; dx++ {
+			var sumAB, sumA2, sumB2 float64
+			for y := nowcastMaxShift; y < next.size-nowcastMaxShift; y++ {
+				for x := nowcastMaxShift; x < next.size-nowcastMaxShift; x++ {
+					a := prev.at(x-dx, y-dy)
+					b := next.at(x, y)
+					sumAB += a * b
+					sumA2 += a * a
+					sumB2 += b * b
+				}
+			}
+			if sumA2 == 0 || sumB2 == 0 {
+				continue
+			}
+
+			score := sumAB / math.Sqrt(sumA2*sumB2)
+			// Em caso de empate, prefere o menor deslocamento
+			if score > bestScore || (score == bestScore && dx*dx+dy*dy < bestDX*bestDX+bestDY*bestDY) {
+				bestScore, bestDX, bestDY = score, dx, dy
+				found = true
+			}
+		}
+	}
+
+	return bestDX, bestDY, found
+}
+
+// neighborhoodMean é a média 3x3 ao redor da posição (x, y), reduzindo o ruído
+// de pixels isolados.
+func neighborhoodMean(g *radarGrid, x, y float64) float64 {
+	cx, cy := int(math.Round(x)), int(math.Round(y))
+	var sum float64
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			sum += g.at(cx+dx, cy+dy)
+		}
+	}
+	return sum / 9
+}
+
+// rainRate converte refletividade em intensidade de chuva (mm/h) pela relação
+// de Marshall-Palmer (Z = 200·R^1,6). Ecos fracos são tratados como ruído.
+func rainRate(dbz float64) float64 {
+	if math.IsInf(dbz, -1) || dbz < 10 {
+		return 0
+	}
+	z := math.Pow(10, dbz/10)
+	return math.Pow(z/200, 1/1.6)
+}
+
+// lonLatToPixel converte coordenadas em pixels globais da projeção Web Mercator.
+// Latitudes além do limite da projeção são levadas à borda do mapa.
+func lonLatToPixel(lat, lon float64, zoom int) (float64, float64) {
+	worldSize := math.Exp2(float64(zoom)) * rainviewer.TileSize
+	lat = max(-maxMercatorLat, min(maxMercatorLat, lat))
+	latRad := lat * math.Pi / 180
+	x := (lon + 180) / 360 * worldSize
+	y := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * worldSize
+	return x, y
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// PromoCodeService é um serviço para códigos promocionais de campanha.
+type PromoCodeService struct {
+	repo                *database.PromoCodeRepository
+	subscriptionService *SubscriptionService
+}
+
+// NewPromoCodeService cria uma nova instância de PromoCodeService.
+func NewPromoCodeService(repo *database.PromoCodeRepository, subscriptionService *SubscriptionService) *PromoCodeService {
+	return &PromoCodeService{
+		repo:                repo,
+		subscriptionService: subscriptionService,
+	}
+}
+
+// CreatePromoCode cadastra um código promocional.
+func (s *PromoCodeService) CreatePromoCode(ctx context.Context, promo *domain.PromoCode) error {
+	if err := promo.Validate(); err != nil {
+		return err
+	}
+	return s.repo.CreatePromoCode(ctx, promo)
+}
+
+// ListPromoCodes lista os códigos promocionais com seus resgates.
+func (s *PromoCodeService) ListPromoCodes(ctx context.Context) ([]*domain.PromoCode, error) {
+	return s.repo.ListPromoCodes(ctx)
+}
+
+// RedeemPromoCode resgata um código de duração e concede os dias ao usuário.
+// Códigos percentuais só valem no checkout web.
+func (s *PromoCodeService) RedeemPromoCode(ctx context.Context, userID uuid.UUID, code string) (*domain.Subscription, error) {
+	promo, err := s.repo.GetPromoCodeByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if promo.Kind != domain.PromoKindDuration {
+		return nil, domain.ErrPromoCodeNotApplicable
+	}
+
+	promo, err = s.repo.RedeemPromoCode(ctx, code, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.subscriptionService.GrantDays(ctx, userID, promo.PlanLevel, promo.DurationDays, domain.SubscriptionEventGrantPromoCode, map[string]any{
+		"promo_code_id": promo.ID,
+		"code":          promo.Code,
+		"days":          promo.DurationDays,
+	})
+	if err != nil {
+		// Sem os dias concedidos, o resgate é desfeito para não consumir o
+		// código (nem a vaga de uso único do usuário) em troca de nada
+		if releaseErr := s.repo.ReleasePromoCodeRedemption(context.WithoutCancel(ctx), promo.ID, userID); releaseErr != nil {
+			log.Printf("failed to release promo code %s redemption for user %s: %v", promo.ID, userID, releaseErr)
+		}
+		return nil, err
+	}
+	return sub, nil
+}
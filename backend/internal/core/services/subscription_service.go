@@ -2,21 +2,68 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/clients/appstore"
+	"github.com/weatherpro/backend/internal/platform/clients/googleplay"
+	"github.com/weatherpro/backend/internal/platform/clients/stripe"
 	"github.com/weatherpro/backend/internal/platform/database"
 )
 
+// SubscriptionLifecycle configura os prazos do ciclo de vida das assinaturas.
+type SubscriptionLifecycle struct {
+	// TrialPlan e TrialPeriod definem o período de teste concedido pelo backend.
+	TrialPlan   string
+	TrialPeriod time.Duration
+	// GracePeriod é quanto tempo uma assinatura sem pagamento mantém o plano
+	// antes de expirar.
+	GracePeriod time.Duration
+}
+
 // SubscriptionService é um serviço para assinaturas.
 type SubscriptionService struct {
-	repo *database.SubscriptionRepository
+	repo             *database.SubscriptionRepository
+	eventRepo        *database.SubscriptionEventRepository
+	googlePlay       *googleplay.Client
+	pushVerifier     *googleplay.PushVerifier
+	appStoreVerifier *appstore.Verifier
+	stripe           *stripe.Client
+	promoRepo        *database.PromoCodeRepository
+	// productPlans mapeia o ID do produto nas lojas (ou do preço na Stripe)
+	// para o nível do plano.
+	productPlans map[string]string
+	lifecycle    SubscriptionLifecycle
 }
 
 // NewSubscriptionService cria uma nova instância de SubscriptionService.
-func NewSubscriptionService(repo *database.SubscriptionRepository) *SubscriptionService {
+func NewSubscriptionService(
+	repo *database.SubscriptionRepository,
+	eventRepo *database.SubscriptionEventRepository,
+	googlePlay *googleplay.Client,
+	pushVerifier *googleplay.PushVerifier,
+	appStoreVerifier *appstore.Verifier,
+	stripeClient *stripe.Client,
+	promoRepo *database.PromoCodeRepository,
+	productPlans map[string]string,
+	lifecycle SubscriptionLifecycle,
+) *SubscriptionService {
 	return &SubscriptionService{
-		repo: repo,
+		repo:             repo,
+		eventRepo:        eventRepo,
+		googlePlay:       googlePlay,
+		pushVerifier:     pushVerifier,
+		appStoreVerifier: appStoreVerifier,
+		stripe:           stripeClient,
+		promoRepo:        promoRepo,
+		productPlans:     productPlans,
+		lifecycle:        lifecycle,
 	}
 }
 
@@ -25,7 +72,805 @@ func (s *SubscriptionService) GetSubscriptionByUserID(ctx context.Context, userI
 	return s.repo.GetSubscriptionByUserID(ctx, userID)
 }
 
-// UpdateSubscription atualiza a assinatura de um usuário.
-func (s *SubscriptionService) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
-	return s.repo.UpdateSubscription(ctx, sub)
+// VerifyPurchase valida a compra junto à loja e deriva a assinatura do usuário
+// exclusivamente a partir da resposta da loja.
+func (s *SubscriptionService) VerifyPurchase(ctx context.Context, userID uuid.UUID, req *domain.PurchaseVerification) (*domain.Subscription, error) {
+	var sub *domain.Subscription
+	var err error
+
+	switch req.Provider {
+	case domain.ProviderGooglePlay:
+		sub, err = s.verifyGooglePlay(ctx, req.PurchaseToken)
+	case domain.ProviderAppStore:
+		sub, err = s.verifyAppStore(req.SignedTransaction)
+	default:
+		return nil, fmt.Errorf("%w: unknown provider %q", domain.ErrInvalidPurchase, req.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetSubscriptionByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		current = &domain.Subscription{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+	if !domain.CanTransition(current.Status, sub.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrInvalidTransition, current.Status, sub.Status)
+	}
+	if sub.Status == domain.SubscriptionTrialing && current.TrialUsedAt != nil &&
+		current.ProviderSubscriptionID != sub.ProviderSubscriptionID {
+		return nil, domain.ErrTrialAlreadyUsed
+	}
+
+	event := &domain.SubscriptionEvent{
+		Provider:               sub.Provider,
+		ProviderSubscriptionID: sub.ProviderSubscriptionID,
+		EventType:              "purchase_verified",
+	}
+	if err := s.applyStoreUpdate(ctx, current, sub, event); err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *SubscriptionService) verifyGooglePlay(ctx context.Context, purchaseToken string) (*domain.Subscription, error) {
+	if purchaseToken == "" {
+		return nil, fmt.Errorf("%w: purchase_token is required", domain.ErrInvalidPurchase)
+	}
+
+	purchase, err := s.googlePlay.GetSubscription(ctx, purchaseToken)
+	if errors.Is(err, googleplay.ErrPurchaseNotFound) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPurchase, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if purchase.SubscriptionState == googleplay.StatePending {
+		return nil, fmt.Errorf("%w: purchase is not paid", domain.ErrInvalidPurchase)
+	}
+
+	sub, err := s.subscriptionFromGooglePlay(purchase, purchaseToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.googlePlay.AcknowledgeIfPending(ctx, purchase, purchaseToken); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// subscriptionFromGooglePlay deriva a assinatura do estado informado pela
+// Google Play.
+func (s *SubscriptionService) subscriptionFromGooglePlay(purchase *googleplay.SubscriptionPurchase, purchaseToken string) (*domain.Subscription, error) {
+	if len(purchase.LineItems) == 0 {
+		return nil, fmt.Errorf("%w: purchase has no line items", domain.ErrInvalidPurchase)
+	}
+
+	planLevel, err := s.planForProduct(purchase.LineItems[0].ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	for _, item := range purchase.LineItems {
+		if item.ExpiryTime.After(expiresAt) {
+			expiresAt = item.ExpiryTime
+		}
+	}
+
+	return &domain.Subscription{
+		PlanLevel:              planLevel,
+		CurrentPeriodEndsAt:    expiresAt,
+		Provider:               domain.ProviderGooglePlay,
+		ProviderSubscriptionID: purchaseToken,
+		Status:                 googlePlayStatus(purchase.SubscriptionState),
+	}, nil
+}
+
+// googlePlayStatus traduz o estado da Google Play para o ciclo de vida local.
+// Pausada ou pendente, a assinatura existe mas não dá direito ao plano.
+func googlePlayStatus(state string) string {
+	switch state {
+	case googleplay.StateActive:
+		return domain.SubscriptionActive
+	case googleplay.StateInGracePeriod:
+		return domain.SubscriptionInGrace
+	case googleplay.StateOnHold, googleplay.StatePaused, googleplay.StatePending:
+		return domain.SubscriptionOnHold
+	case googleplay.StateCanceled:
+		return domain.SubscriptionCanceled
+	default:
+		return domain.SubscriptionExpired
+	}
+}
+
+func (s *SubscriptionService) verifyAppStore(signedTransaction string) (*domain.Subscription, error) {
+	if signedTransaction == "" {
+		return nil, fmt.Errorf("%w: signed_transaction is required", domain.ErrInvalidPurchase)
+	}
+
+	tx, err := s.appStoreVerifier.VerifyTransaction(signedTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPurchase, err)
+	}
+
+	return s.subscriptionFromAppStore(tx)
+}
+
+// subscriptionFromAppStore deriva a assinatura de uma transação assinada pela
+// App Store.
+func (s *SubscriptionService) subscriptionFromAppStore(tx *appstore.TransactionPayload) (*domain.Subscription, error) {
+	planLevel, err := s.planForProduct(tx.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.SubscriptionActive
+	switch {
+	case tx.RevocationDate != 0 || tx.ExpiresAt().Before(time.Now()):
+		status = domain.SubscriptionExpired
+	case tx.IsFreeTrial():
+		status = domain.SubscriptionTrialing
+	}
+
+	return &domain.Subscription{
+		PlanLevel:              planLevel,
+		CurrentPeriodEndsAt:    tx.ExpiresAt(),
+		Provider:               domain.ProviderAppStore,
+		ProviderSubscriptionID: tx.OriginalTransactionID,
+		Status:                 status,
+	}, nil
+}
+
+// HandleGooglePlayNotification processa uma notificação em tempo real da Google
+// Play recebida via push do Pub/Sub. O estado da assinatura é sempre relido da
+// API da Google Play; a notificação só indica qual assinatura mudou.
+func (s *SubscriptionService) HandleGooglePlayNotification(ctx context.Context, bearerToken string, push *googleplay.PushRequest) error {
+	if err := s.pushVerifier.Verify(ctx, bearerToken); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+	}
+
+	n, err := push.Decode()
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+	}
+	if n.PackageName != s.googlePlay.PackageName() {
+		return fmt.Errorf("%w: unexpected package %q", domain.ErrInvalidStoreNotification, n.PackageName)
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	event := &domain.SubscriptionEvent{
+		Provider:        domain.ProviderGooglePlay,
+		ProviderEventID: push.Message.MessageID,
+		Payload:         payload,
+	}
+
+	switch {
+	case n.SubscriptionNotification != nil:
+		event.EventType = n.SubscriptionNotification.TypeName()
+		event.ProviderSubscriptionID = n.SubscriptionNotification.PurchaseToken
+		if err := s.syncGooglePlay(ctx, n.SubscriptionNotification.PurchaseToken, event); err != nil {
+			return err
+		}
+	case n.VoidedPurchaseNotification != nil:
+		event.EventType = "VOIDED_PURCHASE"
+		event.ProviderSubscriptionID = n.VoidedPurchaseNotification.PurchaseToken
+		if err := s.revoke(ctx, domain.ProviderGooglePlay, n.VoidedPurchaseNotification.PurchaseToken, event); err != nil {
+			return err
+		}
+	case n.TestNotification != nil:
+		event.EventType = "TEST"
+	default:
+		// Notificações de compras únicas não afetam assinaturas
+		event.EventType = "OTHER"
+	}
+
+	return s.recordEvent(ctx, event)
+}
+
+// syncGooglePlay relê a assinatura na Google Play e atualiza o usuário vinculado
+// ao purchase token. Em upgrades e downgrades a Google emite um novo token que
+// aponta para o anterior em linkedPurchaseToken.
+func (s *SubscriptionService) syncGooglePlay(ctx context.Context, purchaseToken string, event *domain.SubscriptionEvent) error {
+	purchase, err := s.googlePlay.GetSubscription(ctx, purchaseToken)
+	if errors.Is(err, googleplay.ErrPurchaseNotFound) {
+		log.Printf("google play notification for unknown purchase: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.subscriptionFromGooglePlay(purchase, purchaseToken)
+	if errors.Is(err, domain.ErrInvalidPurchase) {
+		log.Printf("ignoring google play notification: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := s.findSubscription(ctx, domain.ProviderGooglePlay, purchaseToken)
+	if err != nil {
+		return err
+	}
+	if current == nil && purchase.LinkedPurchaseToken != "" {
+		if current, err = s.findSubscription(ctx, domain.ProviderGooglePlay, purchase.LinkedPurchaseToken); err != nil {
+			return err
+		}
+	}
+	return s.applyStoreUpdate(ctx, current, sub, event)
+}
+
+// HandleAppStoreNotification processa uma App Store Server Notification V2.
+func (s *SubscriptionService) HandleAppStoreNotification(ctx context.Context, signedPayload string) error {
+	n, err := s.appStoreVerifier.VerifyNotification(signedPayload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+	}
+
+	var tx *appstore.TransactionPayload
+	if n.Data.SignedTransactionInfo != "" {
+		if tx, err = s.appStoreVerifier.VerifyTransaction(n.Data.SignedTransactionInfo); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+		}
+	}
+
+	payload, err := json.Marshal(struct {
+		Notification *appstore.NotificationPayload `json:"notification"`
+		Transaction  *appstore.TransactionPayload  `json:"transaction,omitempty"`
+	}{n, tx})
+	if err != nil {
+		return err
+	}
+	event := &domain.SubscriptionEvent{
+		Provider:        domain.ProviderAppStore,
+		ProviderEventID: n.NotificationUUID,
+		EventType:       n.NotificationType,
+		EventSubtype:    n.Subtype,
+		Payload:         payload,
+	}
+	if tx == nil {
+		return s.recordEvent(ctx, event)
+	}
+	event.ProviderSubscriptionID = tx.OriginalTransactionID
+
+	sub, err := s.subscriptionFromAppStore(tx)
+	if errors.Is(err, domain.ErrInvalidPurchase) {
+		log.Printf("ignoring app store notification: %v", err)
+		return s.recordEvent(ctx, event)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch n.NotificationType {
+	case "EXPIRED", "GRACE_PERIOD_EXPIRED", "REFUND", "REVOKE":
+		sub.Status = domain.SubscriptionExpired
+	case "DID_FAIL_TO_RENEW":
+		// Com o Billing Grace Period da Apple o usuário mantém o acesso; sem ele,
+		// a Apple segue tentando cobrar com o acesso suspenso
+		if n.Subtype == "GRACE_PERIOD" {
+			sub.Status = domain.SubscriptionInGrace
+		} else {
+			sub.Status = domain.SubscriptionOnHold
+		}
+	case "DID_CHANGE_RENEWAL_STATUS":
+		if n.Subtype == "AUTO_RENEW_DISABLED" && sub.Status != domain.SubscriptionExpired {
+			sub.Status = domain.SubscriptionCanceled
+		}
+	}
+
+	current, err := s.findSubscription(ctx, domain.ProviderAppStore, tx.OriginalTransactionID)
+	if err != nil {
+		return err
+	}
+	if err := s.applyStoreUpdate(ctx, current, sub, event); err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, event)
+}
+
+// CreateCheckoutSession cria uma sessão de checkout da Stripe para o usuário
+// assinar pela web. A assinatura só é gravada quando o webhook confirma o pagamento.
+func (s *SubscriptionService) CreateCheckoutSession(ctx context.Context, userID uuid.UUID, req *domain.CheckoutRequest) (*domain.CheckoutSession, error) {
+	if _, err := s.planForProduct(req.PriceID); err != nil {
+		return nil, err
+	}
+	if req.SuccessURL == "" || req.CancelURL == "" {
+		return nil, fmt.Errorf("%w: success_url and cancel_url are required", domain.ErrInvalidPurchase)
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		PriceID:           req.PriceID,
+		ClientReferenceID: userID.String(),
+		CustomerEmail:     req.Email,
+		SuccessURL:        req.SuccessURL,
+		CancelURL:         req.CancelURL,
+		Metadata:          map[string]string{"user_id": userID.String()},
+	}
+
+	// O desconto é aplicado pelo cupom da Stripe; o resgate só é contado quando
+	// o checkout é concluído
+	if req.PromoCode != "" {
+		promo, err := s.checkoutPromoCode(ctx, userID, req.PromoCode)
+		if err != nil {
+			return nil, err
+		}
+		params.CouponID = promo.StripeCouponID
+		params.Metadata["promo_code"] = promo.Code
+	}
+
+	session, err := s.stripe.CreateCheckoutSession(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CheckoutSession{ID: session.ID, URL: session.URL}, nil
+}
+
+func (s *SubscriptionService) checkoutPromoCode(ctx context.Context, userID uuid.UUID, code string) (*domain.PromoCode, error) {
+	promo, err := s.promoRepo.GetPromoCodeByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if promo.Kind != domain.PromoKindPercent {
+		return nil, fmt.Errorf("%w: redeem duration codes at /subscription/promo-codes/redeem", domain.ErrPromoCodeNotApplicable)
+	}
+	if err := promo.Redeemable(time.Now()); err != nil {
+		return nil, err
+	}
+
+	redeemed, err := s.promoRepo.HasRedeemed(ctx, promo.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if redeemed {
+		return nil, domain.ErrPromoCodeAlreadyRedeemed
+	}
+	return promo, nil
+}
+
+// HandleStripeEvent processa um webhook de cobrança da Stripe. Como nas lojas,
+// o estado da assinatura é relido da API em vez de confiar no corpo do evento.
+func (s *SubscriptionService) HandleStripeEvent(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.stripe.ConstructEvent(payload, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+	}
+
+	record := &domain.SubscriptionEvent{
+		Provider:        domain.ProviderStripe,
+		ProviderEventID: event.ID,
+		EventType:       event.Type,
+		Payload:         event.Data.Object,
+	}
+
+	switch event.Type {
+	case stripe.EventCheckoutSessionCompleted:
+		var session stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+		}
+		if session.Mode != "subscription" || session.Subscription == "" {
+			break
+		}
+		userID, err := uuid.Parse(session.ClientReferenceID)
+		if err != nil {
+			log.Printf("stripe checkout session %s without a valid user: %v", session.ID, err)
+			break
+		}
+		record.ProviderSubscriptionID = session.Subscription
+		if err := s.syncStripe(ctx, session.Subscription, &userID, record); err != nil {
+			return err
+		}
+		if code := session.Metadata["promo_code"]; code != "" {
+			// O desconto já foi cobrado pela Stripe; um limite atingido no meio
+			// tempo fica apenas registrado
+			if _, err := s.promoRepo.RedeemPromoCode(ctx, code, userID); err != nil {
+				log.Printf("failed to record promo code %s for checkout %s: %v", code, session.ID, err)
+			}
+		}
+	case stripe.EventInvoicePaid:
+		var invoice stripe.Invoice
+		if err := json.Unmarshal(event.Data.Object, &invoice); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+		}
+		if invoice.SubscriptionID() == "" {
+			break
+		}
+		record.ProviderSubscriptionID = invoice.SubscriptionID()
+		if err := s.syncStripe(ctx, invoice.SubscriptionID(), nil, record); err != nil {
+			return err
+		}
+	case stripe.EventCustomerSubscriptionDeleted:
+		var sub stripe.Subscription
+		if err := json.Unmarshal(event.Data.Object, &sub); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidStoreNotification, err)
+		}
+		record.ProviderSubscriptionID = sub.ID
+		if err := s.revoke(ctx, domain.ProviderStripe, sub.ID, record); err != nil {
+			return err
+		}
+	}
+
+	return s.recordEvent(ctx, record)
+}
+
+// syncStripe relê a assinatura na Stripe e a grava no usuário vinculado. No
+// checkout o usuário vem da sessão; depois, da própria assinatura já gravada
+// ou do user_id copiado para os metadados da assinatura.
+func (s *SubscriptionService) syncStripe(ctx context.Context, subscriptionID string, userID *uuid.UUID, event *domain.SubscriptionEvent) error {
+	remote, err := s.stripe.GetSubscription(ctx, subscriptionID)
+	if errors.Is(err, stripe.ErrNotFound) {
+		log.Printf("stripe event for unknown subscription: %s", subscriptionID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	planLevel, err := s.planForProduct(remote.PriceID())
+	if errors.Is(err, domain.ErrInvalidPurchase) {
+		log.Printf("ignoring stripe event: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sub := &domain.Subscription{
+		PlanLevel:              planLevel,
+		CurrentPeriodEndsAt:    remote.PeriodEnd(),
+		Provider:               domain.ProviderStripe,
+		ProviderSubscriptionID: remote.ID,
+		Status:                 stripeStatus(remote),
+	}
+
+	current, err := s.findSubscription(ctx, domain.ProviderStripe, remote.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil && userID == nil {
+		if id, err := uuid.Parse(remote.Metadata["user_id"]); err == nil {
+			userID = &id
+		}
+	}
+	if current == nil && userID != nil {
+		current, err = s.repo.GetSubscriptionByUserID(ctx, *userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			current = &domain.Subscription{UserID: *userID}
+		} else if err != nil {
+			return err
+		}
+	}
+	return s.applyStoreUpdate(ctx, current, sub, event)
+}
+
+// stripeStatus traduz o status da Stripe para o ciclo de vida local. past_due
+// mantém o plano em carência enquanto a Stripe tenta cobrar novamente.
+func stripeStatus(sub *stripe.Subscription) string {
+	switch sub.Status {
+	case stripe.StatusTrialing, stripe.StatusActive:
+		if sub.CancelAtPeriodEnd {
+			return domain.SubscriptionCanceled
+		}
+		if sub.Status == stripe.StatusTrialing {
+			return domain.SubscriptionTrialing
+		}
+		return domain.SubscriptionActive
+	case stripe.StatusPastDue:
+		return domain.SubscriptionInGrace
+	case stripe.StatusUnpaid, stripe.StatusPaused, stripe.StatusIncomplete:
+		return domain.SubscriptionOnHold
+	default:
+		return domain.SubscriptionExpired
+	}
+}
+
+// revoke remove o direito ao plano de uma assinatura reembolsada ou encerrada.
+func (s *SubscriptionService) revoke(ctx context.Context, provider, providerSubscriptionID string, event *domain.SubscriptionEvent) error {
+	current, err := s.findSubscription(ctx, provider, providerSubscriptionID)
+	if err != nil || current == nil {
+		return err
+	}
+	sub := *current
+	sub.Status = domain.SubscriptionExpired
+	return s.applyStoreUpdate(ctx, current, &sub, event)
+}
+
+// applyStoreUpdate grava a assinatura derivada da loja no usuário que já a
+// possui. Notificações de assinaturas que nenhum usuário verificou ainda são
+// apenas registradas; o vínculo acontece em VerifyPurchase. Transições fora do
+// ciclo de vida não são aplicadas e ficam registradas como rejeitadas.
+func (s *SubscriptionService) applyStoreUpdate(ctx context.Context, current, sub *domain.Subscription, event *domain.SubscriptionEvent) error {
+	if current == nil {
+		return nil
+	}
+
+	userID := current.UserID
+	s.applyGrantedDays(current, sub, time.Now())
+	event.UserID = &userID
+	event.StatusFrom = current.Status
+	event.StatusTo = sub.Status
+
+	if !domain.CanTransition(current.Status, sub.Status) {
+		log.Printf("rejected subscription transition %s -> %s for user %s", current.Status, sub.Status, userID)
+		return s.recordEvent(ctx, &domain.SubscriptionEvent{
+			UserID:                 &userID,
+			Provider:               sub.Provider,
+			ProviderSubscriptionID: sub.ProviderSubscriptionID,
+			EventType:              domain.SubscriptionEventTransitionRejected,
+			StatusFrom:             current.Status,
+			StatusTo:               sub.Status,
+		})
+	}
+
+	sub.UserID = userID
+	s.prepareTransition(current, sub, time.Now())
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	event.PlanLevel = sub.PlanLevel
+	event.CurrentPeriodEndsAt = &sub.CurrentPeriodEndsAt
+	return nil
+}
+
+// applyGrantedDays soma ao período informado pelo provedor cobrado os dias
+// concedidos por promoções e indicações. Ao trocar uma assinatura promocional
+// por uma cobrada, o que restava dela vira dias concedidos.
+// Enquanto os dias concedidos não acabam, uma assinatura expirada na loja
+// continua como cancelada, com direito ao plano até o fim do período estendido.
+func (s *SubscriptionService) applyGrantedDays(current, next *domain.Subscription, now time.Time) {
+	if !domain.IsBilledProvider(next.Provider) {
+		return
+	}
+
+	next.GrantedDays = current.GrantedDays
+	if current.Provider == domain.ProviderPromo && current.EffectivePlan(now) != domain.PlanFree {
+		remaining := current.CurrentPeriodEndsAt.Sub(now)
+		next.GrantedDays += int((remaining + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	if next.GrantedDays == 0 {
+		return
+	}
+
+	next.CurrentPeriodEndsAt = next.CurrentPeriodEndsAt.Add(time.Duration(next.GrantedDays) * 24 * time.Hour)
+	if next.Status == domain.SubscriptionExpired {
+		if next.CurrentPeriodEndsAt.After(now) && domain.CanTransition(current.Status, domain.SubscriptionCanceled) {
+			next.Status = domain.SubscriptionCanceled
+		} else {
+			next.GrantedDays = 0
+		}
+	}
+}
+
+// GrantDays concede dias de plano ao usuário e registra a concessão. Quem tem
+// uma assinatura cobrada em andamento recebe os dias depois do período pago, e
+// eles continuam valendo a cada renovação sincronizada; os demais passam a ter
+// uma assinatura promocional que estende o tempo de plano que ainda restava.
+func (s *SubscriptionService) GrantDays(ctx context.Context, userID uuid.UUID, planLevel string, days int, eventType string, payload any) (*domain.Subscription, error) {
+	now := time.Now()
+	current, err := s.repo.GetSubscriptionByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		current = &domain.Subscription{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	grant := time.Duration(days) * 24 * time.Hour
+	next := *current
+	if domain.IsBilledProvider(current.Provider) && current.Status != domain.SubscriptionExpired {
+		next.GrantedDays += days
+		next.CurrentPeriodEndsAt = current.CurrentPeriodEndsAt.Add(grant)
+	} else {
+		start := now
+		if current.EffectivePlan(now) != domain.PlanFree {
+			if current.CurrentPeriodEndsAt.After(now) {
+				start = current.CurrentPeriodEndsAt
+			}
+		} else {
+			next.PlanLevel = planLevel
+		}
+		next.Provider = domain.ProviderPromo
+		next.ProviderSubscriptionID = domain.ProviderPromo + ":" + userID.String()
+		next.Status = domain.SubscriptionActive
+		next.GracePeriodEndsAt = nil
+		next.GrantedDays = 0
+		next.CurrentPeriodEndsAt = start.Add(grant)
+	}
+
+	if !domain.CanTransition(current.Status, next.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrInvalidTransition, current.Status, next.Status)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSubscription(ctx, &next); err != nil {
+		return nil, err
+	}
+
+	// Os dias já foram concedidos: uma falha no registro do evento não é
+	// devolvida, para que quem chamou não trate a concessão como perdida
+	err = s.recordEvent(ctx, &domain.SubscriptionEvent{
+		UserID:                 &userID,
+		Provider:               next.Provider,
+		ProviderSubscriptionID: next.ProviderSubscriptionID,
+		EventType:              eventType,
+		PlanLevel:              next.PlanLevel,
+		CurrentPeriodEndsAt:    &next.CurrentPeriodEndsAt,
+		StatusFrom:             current.Status,
+		StatusTo:               next.Status,
+		Payload:                raw,
+	})
+	if err != nil {
+		log.Printf("failed to record %s event for user %s: %v", eventType, userID, err)
+	}
+	return &next, nil
+}
+
+// prepareTransition preenche os prazos do novo estado: a carência começa ao
+// entrar em in_grace ou on_hold e é preservada enquanto o estado não muda, e o
+// primeiro período de teste fica marcado para não se repetir.
+func (s *SubscriptionService) prepareTransition(current, next *domain.Subscription, now time.Time) {
+	switch next.Status {
+	case domain.SubscriptionInGrace, domain.SubscriptionOnHold:
+		if next.GracePeriodEndsAt == nil {
+			if current.Status == next.Status && current.GracePeriodEndsAt != nil {
+				next.GracePeriodEndsAt = current.GracePeriodEndsAt
+			} else {
+				graceEndsAt := now.Add(s.lifecycle.GracePeriod)
+				next.GracePeriodEndsAt = &graceEndsAt
+			}
+		}
+	default:
+		next.GracePeriodEndsAt = nil
+	}
+
+	next.TrialUsedAt = current.TrialUsedAt
+	if next.Status == domain.SubscriptionTrialing && next.TrialUsedAt == nil {
+		next.TrialUsedAt = &now
+	}
+}
+
+// StartTrial concede o período de teste do backend. Cada usuário tem direito a
+// um único teste, e só quando não tem uma assinatura em andamento.
+func (s *SubscriptionService) StartTrial(ctx context.Context, userID uuid.UUID) (*domain.Subscription, error) {
+	if s.lifecycle.TrialPeriod <= 0 {
+		return nil, domain.ErrTrialAlreadyUsed
+	}
+
+	now := time.Now()
+	sub := &domain.Subscription{
+		UserID:                 userID,
+		PlanLevel:              s.lifecycle.TrialPlan,
+		CurrentPeriodEndsAt:    now.Add(s.lifecycle.TrialPeriod),
+		Provider:               domain.ProviderTrial,
+		ProviderSubscriptionID: domain.ProviderTrial + ":" + userID.String(),
+		Status:                 domain.SubscriptionTrialing,
+		TrialUsedAt:            &now,
+	}
+
+	started, err := s.repo.StartTrial(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		current, err := s.repo.GetSubscriptionByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if current.TrialUsedAt != nil {
+			return nil, domain.ErrTrialAlreadyUsed
+		}
+		return nil, fmt.Errorf("%w: subscription is %s", domain.ErrInvalidTransition, current.Status)
+	}
+
+	err = s.recordEvent(ctx, &domain.SubscriptionEvent{
+		UserID:                 &userID,
+		Provider:               sub.Provider,
+		ProviderSubscriptionID: sub.ProviderSubscriptionID,
+		EventType:              domain.SubscriptionEventTrialStarted,
+		PlanLevel:              sub.PlanLevel,
+		CurrentPeriodEndsAt:    &sub.CurrentPeriodEndsAt,
+		StatusTo:               sub.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RunLifecycle avança periodicamente as assinaturas cujo prazo passou sem
+// notícia da loja: períodos encerrados entram em carência e carências
+// esgotadas, cancelamentos vencidos e testes encerrados expiram.
+func (s *SubscriptionService) RunLifecycle(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		due, err := s.repo.ListLifecycleDue(ctx, now)
+		if err != nil {
+			log.Printf("failed to list subscriptions due for lifecycle: %v", err)
+		}
+		for _, sub := range due {
+			if err := s.advanceLifecycle(ctx, sub, now); err != nil {
+				log.Printf("failed to advance subscription of user %s: %v", sub.UserID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SubscriptionService) advanceLifecycle(ctx context.Context, sub *domain.Subscription, now time.Time) error {
+	next := domain.SubscriptionExpired
+	eventType := domain.SubscriptionEventExpired
+	var graceEndsAt *time.Time
+
+	// Renovações pagas que não chegaram a tempo ganham a carência contada do
+	// fim do período; o tempo concedido pelo backend não tem cobrança e expira direto
+	if (sub.Status == domain.SubscriptionActive || sub.Status == domain.SubscriptionTrialing) && domain.IsBilledProvider(sub.Provider) {
+		endsAt := sub.CurrentPeriodEndsAt.Add(s.lifecycle.GracePeriod)
+		if endsAt.After(now) {
+			next = domain.SubscriptionInGrace
+			eventType = domain.SubscriptionEventGracePeriodStarted
+			graceEndsAt = &endsAt
+		}
+	}
+
+	changed, err := s.repo.TransitionStatus(ctx, sub.UserID, sub.Status, next, graceEndsAt)
+	if err != nil || !changed {
+		return err
+	}
+
+	userID := sub.UserID
+	return s.recordEvent(ctx, &domain.SubscriptionEvent{
+		UserID:                 &userID,
+		Provider:               sub.Provider,
+		ProviderSubscriptionID: sub.ProviderSubscriptionID,
+		EventType:              eventType,
+		PlanLevel:              sub.PlanLevel,
+		CurrentPeriodEndsAt:    &sub.CurrentPeriodEndsAt,
+		StatusFrom:             sub.Status,
+		StatusTo:               next,
+	})
+}
+
+func (s *SubscriptionService) findSubscription(ctx context.Context, provider, providerSubscriptionID string) (*domain.Subscription, error) {
+	sub, err := s.repo.GetSubscriptionByProviderID(ctx, provider, providerSubscriptionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+func (s *SubscriptionService) recordEvent(ctx context.Context, event *domain.SubscriptionEvent) error {
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+	_, err := s.eventRepo.CreateSubscriptionEvent(ctx, event)
+	return err
+}
+
+func (s *SubscriptionService) planForProduct(productID string) (string, error) {
+	planLevel, ok := s.productPlans[productID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown product %q", domain.ErrInvalidPurchase, productID)
+	}
+	return planLevel, nil
 }
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/database"
+)
+
+// syncMaxAttempts limita quantas vezes uma alteração é reavaliada quando o
+// registro muda entre a leitura e a gravação.
+const syncMaxAttempts = 3
+
+// SyncService sincroniza as cidades favoritas e as configurações de
+// notificação entre os aparelhos do usuário. Cada registro tem uma versão;
+// quando aparelho e servidor alteraram o mesmo registro, vence a alteração
+// mais recente e o conflito é informado ao aparelho.
+type SyncService struct {
+	repo                     *database.SyncRepository
+	favoriteCityRepo         *database.FavoriteCityRepository
+	notificationSettingsRepo *database.NotificationSettingsRepository
+	favoriteCityService      *FavoriteCityService
+}
+
+// NewSyncService cria uma nova instância de SyncService.
+func NewSyncService(repo *database.SyncRepository, favoriteCityRepo *database.FavoriteCityRepository, notificationSettingsRepo *database.NotificationSettingsRepository, favoriteCityService *FavoriteCityService) *SyncService {
+	return &SyncService{
+		repo:                     repo,
+		favoriteCityRepo:         favoriteCityRepo,
+		notificationSettingsRepo: notificationSettingsRepo,
+		favoriteCityService:      favoriteCityService,
+	}
+}
+
+// GetChanges obtém o que mudou desde o cursor, ou o estado completo quando o
+// cursor é vazio.
+func (s *SyncService) GetChanges(ctx context.Context, userID uuid.UUID, cursor string) (*domain.SyncChanges, error) {
+	var since *int64
+	if cursor != "" {
+		version, err := domain.ParseSyncCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		since = &version
+	}
+	return s.repo.GetSyncChanges(ctx, userID, since)
+}
+
+// Push aplica as alterações feitas no aparelho e informa o resultado de cada
+// uma, na ordem do envio. Alterações inválidas são rejeitadas sem impedir as
+// demais.
+func (s *SyncService) Push(ctx context.Context, userID uuid.UUID, push *domain.SyncPush) ([]*domain.SyncChangeResult, error) {
+	total := len(push.Favorites)
+	if push.NotificationSettings != nil {
+		total++
+	}
+	if total == 0 || total > domain.MaxSyncChanges {
+		return nil, fmt.Errorf("%w: between 1 and %d changes are required", domain.ErrInvalidSyncPush, domain.MaxSyncChanges)
+	}
+	for _, change := range push.Favorites {
+		if change == nil {
+			return nil, fmt.Errorf("%w: favorites must not contain null", domain.ErrInvalidSyncPush)
+		}
+		if err := change.SyncChange.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if push.NotificationSettings != nil {
+		if err := push.NotificationSettings.SyncChange.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	results := make([]*domain.SyncChangeResult, 0, total)
+	for _, change := range push.Favorites {
+		result, err := s.pushFavoriteCity(ctx, userID, change, now)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if push.NotificationSettings != nil {
+		result, err := s.pushNotificationSettings(ctx, userID, push.NotificationSettings, now)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *SyncService) pushFavoriteCity(ctx context.Context, userID uuid.UUID, change *domain.FavoriteCityChange, now time.Time) (*domain.SyncChangeResult, error) {
+	city := change.FavoriteCity
+	city.UserID = userID
+	city.UpdatedAt = modifiedAt(change.SyncChange, now)
+	city.Current = nil
+
+	result := &domain.SyncChangeResult{Entity: domain.SyncEntityFavoriteCity, ID: city.ID}
+	if city.ID == uuid.Nil {
+		return rejectSyncChange(result, fmt.Errorf("%w: id is required", domain.ErrInvalidFavoriteCity)), nil
+	}
+
+	for range syncMaxAttempts {
+		current, err := s.favoriteCityRepo.GetFavoriteCityByID(ctx, city.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			current = nil
+		} else if err != nil {
+			return nil, err
+		}
+		if current != nil && current.UserID != userID {
+			return rejectSyncChange(result, domain.ErrFavoriteCityNotFound), nil
+		}
+
+		// A versão e o horário do servidor vêm do registro ou da remoção dele
+		var serverVersion int64
+		var serverTime time.Time
+		if current != nil {
+			serverVersion, serverTime = current.Version, current.UpdatedAt
+		} else {
+			tombstone, err := s.repo.GetTombstone(ctx, userID, domain.SyncEntityFavoriteCity, city.ID)
+			if err != nil {
+				return nil, err
+			}
+			if tombstone != nil {
+				serverVersion, serverTime = tombstone.Version, tombstone.DeletedAt
+			}
+		}
+
+		conflict, serverWins := resolveSyncConflict(change.BaseVersion, serverVersion, serverTime, city.UpdatedAt)
+		if serverWins {
+			result.Status = domain.SyncStatusConflict
+			result.Winner = domain.SyncWinnerServer
+			result.Version = serverVersion
+			result.Server = current
+			return result, nil
+		}
+
+		switch {
+		case change.Deleted && current == nil:
+			// Já apagada: nada a fazer
+		case change.Deleted:
+			err = s.favoriteCityRepo.DeleteSyncedFavoriteCity(ctx, userID, city.ID, current.Version, city.UpdatedAt)
+		case current == nil:
+			if err = s.favoriteCityService.ValidateFavoriteCity(ctx, &city); err == nil {
+				if err = s.favoriteCityService.CheckFavoriteCityLimit(ctx, userID); err == nil {
+					err = s.favoriteCityRepo.CreateSyncedFavoriteCity(ctx, &city)
+				}
+			}
+		default:
+			if err = s.favoriteCityService.ValidateFavoriteCity(ctx, &city); err == nil {
+				err = s.favoriteCityRepo.UpdateSyncedFavoriteCity(ctx, &city, current.Version)
+			}
+		}
+		if errors.Is(err, domain.ErrSyncVersionMismatch) {
+			continue
+		}
+		if isRejectedSyncError(err) {
+			return rejectSyncChange(result, err), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result.Status = domain.SyncStatusApplied
+		if conflict {
+			result.Status = domain.SyncStatusConflict
+			result.Winner = domain.SyncWinnerClient
+		}
+		if !change.Deleted {
+			result.Version = city.Version
+		}
+		return result, nil
+	}
+
+	return rejectSyncChange(result, domain.ErrSyncVersionMismatch), nil
+}
+
+func (s *SyncService) pushNotificationSettings(ctx context.Context, userID uuid.UUID, change *domain.NotificationSettingsChange, now time.Time) (*domain.SyncChangeResult, error) {
+	settings := change.NotificationSettings
+	settings.UserID = userID
+	settings.UpdatedAt = modifiedAt(change.SyncChange, now)
+
+	result := &domain.SyncChangeResult{Entity: domain.SyncEntityNotificationSettings, ID: userID}
+	applyNotificationSettingsDefaults(&settings)
+	if err := settings.Validate(); err != nil {
+		return rejectSyncChange(result, err), nil
+	}
+
+	for range syncMaxAttempts {
+		current, err := s.notificationSettingsRepo.GetNotificationSettingsByUserID(ctx, userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			current = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		var serverVersion int64
+		var serverTime time.Time
+		if current != nil {
+			serverVersion, serverTime = current.Version, current.UpdatedAt
+		}
+		conflict, serverWins := resolveSyncConflict(change.BaseVersion, serverVersion, serverTime, settings.UpdatedAt)
+		if serverWins {
+			result.Status = domain.SyncStatusConflict
+			result.Winner = domain.SyncWinnerServer
+			result.Version = serverVersion
+			result.Server = current
+			return result, nil
+		}
+
+		err = s.notificationSettingsRepo.SaveSyncedNotificationSettings(ctx, &settings, serverVersion, current != nil)
+		if errors.Is(err, domain.ErrSyncVersionMismatch) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result.Status = domain.SyncStatusApplied
+		if conflict {
+			result.Status = domain.SyncStatusConflict
+			result.Winner = domain.SyncWinnerClient
+		}
+		result.Version = settings.Version
+		return result, nil
+	}
+
+	return rejectSyncChange(result, domain.ErrSyncVersionMismatch), nil
+}
+
+// resolveSyncConflict indica se o servidor tem uma versão que o aparelho não
+// conhecia e, nesse caso, se ela prevalece por ser mais recente que a
+// alteração do aparelho. Sem registro no servidor, serverVersion é 0 e não há
+// conflito.
+func resolveSyncConflict(baseVersion, serverVersion int64, serverTime, clientTime time.Time) (conflict, serverWins bool) {
+	conflict = serverVersion > baseVersion
+	return conflict, conflict && serverTime.After(clientTime)
+}
+
+// modifiedAt é o horário da alteração no aparelho. Relógios adiantados não
+// podem fazer uma alteração vencer as que ainda vão acontecer.
+func modifiedAt(change domain.SyncChange, now time.Time) time.Time {
+	if change.ModifiedAt.IsZero() || change.ModifiedAt.After(now) {
+		return now
+	}
+	return change.ModifiedAt
+}
+
+func rejectSyncChange(result *domain.SyncChangeResult, err error) *domain.SyncChangeResult {
+	result.Status = domain.SyncStatusRejected
+	result.Error = err.Error()
+	return result
+}
+
+// isRejectedSyncError indica os erros causados pela própria alteração, que a
+// rejeitam sem falhar a sincronização inteira.
+func isRejectedSyncError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidFavoriteCity) ||
+		errors.Is(err, domain.ErrFavoriteCityExists) ||
+		errors.Is(err, domain.ErrPlanLimit)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+func TestResolveSyncConflict(t *testing.T) {
+	earlier := time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Minute)
+
+	tests := []struct {
+		name           string
+		baseVersion    int64
+		serverVersion  int64
+		serverTime     time.Time
+		clientTime     time.Time
+		wantConflict   bool
+		wantServerWins bool
+	}{
+		{name: "new record", baseVersion: 0, serverVersion: 0, clientTime: later},
+		{name: "client saw the latest version", baseVersion: 7, serverVersion: 7, serverTime: later, clientTime: earlier},
+		{name: "client ahead of a deleted record", baseVersion: 9, serverVersion: 7, serverTime: later, clientTime: earlier},
+		{name: "server changed earlier", baseVersion: 6, serverVersion: 7, serverTime: earlier, clientTime: later, wantConflict: true},
+		{name: "server changed later", baseVersion: 6, serverVersion: 7, serverTime: later, clientTime: earlier, wantConflict: true, wantServerWins: true},
+		{name: "same instant goes to the client", baseVersion: 6, serverVersion: 7, serverTime: earlier, clientTime: earlier, wantConflict: true},
+		{name: "record created on another device", baseVersion: 0, serverVersion: 3, serverTime: later, clientTime: earlier, wantConflict: true, wantServerWins: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict, serverWins := resolveSyncConflict(tt.baseVersion, tt.serverVersion, tt.serverTime, tt.clientTime)
+			if conflict != tt.wantConflict || serverWins != tt.wantServerWins {
+				t.Fatalf("resolveSyncConflict() = %v, %v, want %v, %v", conflict, serverWins, tt.wantConflict, tt.wantServerWins)
+			}
+		})
+	}
+}
+
+func TestModifiedAt(t *testing.T) {
+	now := time.Date(2026, time.May, 4, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		modifiedAt time.Time
+		want       time.Time
+	}{
+		{name: "not informed", want: now},
+		{name: "in the past", modifiedAt: now.Add(-time.Hour), want: now.Add(-time.Hour)},
+		{name: "clock ahead", modifiedAt: now.Add(time.Hour), want: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modifiedAt(domain.SyncChange{ModifiedAt: tt.modifiedAt}, now); !got.Equal(tt.want) {
+				t.Fatalf("modifiedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Envios malformados são recusados antes de qualquer acesso ao banco.
+func TestSyncPushRejectsMalformedChanges(t *testing.T) {
+	favorite := func(baseVersion int64) *domain.FavoriteCityChange {
+		return &domain.FavoriteCityChange{
+			FavoriteCity: domain.FavoriteCity{ID: uuid.New(), CityName: "Recife", CountryCode: "BR", Lat: -8.05, Lon: -34.9},
+			SyncChange:   domain.SyncChange{BaseVersion: baseVersion},
+		}
+	}
+
+	tests := []struct {
+		name string
+		push *domain.SyncPush
+	}{
+		{name: "empty", push: &domain.SyncPush{}},
+		{name: "too many changes", push: &domain.SyncPush{Favorites: make([]*domain.FavoriteCityChange, domain.MaxSyncChanges+1)}},
+		{name: "null favorite", push: &domain.SyncPush{Favorites: []*domain.FavoriteCityChange{nil}}},
+		{name: "negative favorite base version", push: &domain.SyncPush{Favorites: []*domain.FavoriteCityChange{favorite(-1)}}},
+		{name: "negative settings base version", push: &domain.SyncPush{
+			NotificationSettings: &domain.NotificationSettingsChange{SyncChange: domain.SyncChange{BaseVersion: -1}},
+		}},
+		{name: "valid favorite before a malformed one", push: &domain.SyncPush{Favorites: []*domain.FavoriteCityChange{favorite(0), favorite(-5)}}},
+	}
+
+	s := &SyncService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Push(context.Background(), uuid.New(), tt.push); !errors.Is(err, domain.ErrInvalidSyncPush) {
+				t.Fatalf("Push() error = %v, want ErrInvalidSyncPush", err)
+			}
+		})
+	}
+}
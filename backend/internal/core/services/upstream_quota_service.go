@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/cache"
+)
+
+// UpstreamLimits são os limites de chamadas da API externa. Zero desliga a janela.
+type UpstreamLimits struct {
+	PerMinute int
+	PerDay    int
+}
+
+type quotaWindow struct {
+	name   string
+	length time.Duration
+	limit  int
+}
+
+// UpstreamQuotaService acompanha as chamadas a uma API externa em janelas
+// deslizantes compartilhadas entre as réplicas e mantém o nível de consumo
+// atual em memória, para ser consultado a cada requisição sem ir ao Valkey.
+type UpstreamQuotaService struct {
+	counter  *cache.SlidingWindowCounter
+	provider string
+	windows  []quotaWindow
+
+	status atomic.Pointer[domain.UpstreamQuotaStatus]
+}
+
+// NewUpstreamQuotaService cria uma nova instância de UpstreamQuotaService.
+func NewUpstreamQuotaService(counter *cache.SlidingWindowCounter, provider string, limits UpstreamLimits) *UpstreamQuotaService {
+	s := &UpstreamQuotaService{
+		counter:  counter,
+		provider: provider,
+		windows: []quotaWindow{
+			{name: "minute", length: time.Minute, limit: limits.PerMinute},
+			{name: "day", length: 24 * time.Hour, limit: limits.PerDay},
+		},
+	}
+	s.status.Store(&domain.UpstreamQuotaStatus{Provider: provider, Level: domain.QuotaLevelNormal})
+	return s
+}
+
+// Record registra calls chamadas feitas agora à API.
+func (s *UpstreamQuotaService) Record(ctx context.Context, calls int) {
+	for _, w := range s.windows {
+		if w.limit <= 0 {
+			continue
+		}
+		if _, err := s.counter.Add(ctx, s.key(w), calls, w.length); err != nil {
+			// Uma falha aqui só subestima o consumo até a próxima leitura
+			log.Printf("failed to record %s calls: %v", s.provider, err)
+		}
+	}
+}
+
+// Level retorna o último nível de consumo conhecido.
+func (s *UpstreamQuotaService) Level() string {
+	return s.status.Load().Level
+}
+
+// GetStatus lê o consumo atual no Valkey e atualiza o nível em memória.
+func (s *UpstreamQuotaService) GetStatus(ctx context.Context) (*domain.UpstreamQuotaStatus, error) {
+	status := &domain.UpstreamQuotaStatus{Provider: s.provider, UpdatedAt: time.Now()}
+	for _, w := range s.windows {
+		if w.limit <= 0 {
+			continue
+		}
+		used, err := s.counter.Count(ctx, s.key(w), w.length)
+		if err != nil {
+			return nil, err
+		}
+		status.Windows = append(status.Windows, domain.QuotaWindowUsage{
+			Window:    w.name,
+			Limit:     int64(w.limit),
+			Used:      used,
+			Remaining: max(0, int64(w.limit)-used),
+		})
+	}
+	status.Level = domain.QuotaLevel(status.Windows)
+
+	if previous := s.status.Swap(status); previous.Level != status.Level {
+		log.Printf("%s quota level changed from %s to %s", s.provider, previous.Level, status.Level)
+	}
+	return status, nil
+}
+
+// RunMonitor atualiza o nível de consumo a cada intervalo até o contexto ser
+// cancelado.
+func (s *UpstreamQuotaService) RunMonitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.GetStatus(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("failed to read %s quota: %v", s.provider, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *UpstreamQuotaService) key(w quotaWindow) string {
+	return "upstream:" + s.provider + ":" + w.name
+}
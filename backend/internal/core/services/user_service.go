@@ -2,26 +2,67 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/platform/database"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// referralCodeAlphabet evita caracteres que se confundem ao digitar (0/O, 1/I).
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// referralRewardWindow é o período em que vale o limite de recompensas de um
+// indicador.
+const referralRewardWindow = 365 * 24 * time.Hour
+
 // UserService é um serviço para usuários.
 type UserService struct {
-	userRepo *database.UserRepository
+	userRepo            *database.UserRepository
+	referralRepo        *database.ReferralRepository
+	subscriptionService *SubscriptionService
+	// referralRewardDays são os dias de premium que indicador e indicado ganham.
+	referralRewardDays int
+	// maxReferralRewards limita as recompensas de um indicador em
+	// referralRewardWindow, para que cadastros descartáveis não rendam premium
+	// sem fim. O indicado sempre ganha seus dias.
+	maxReferralRewards int
 }
 
 // NewUserService cria uma nova instância de UserService.
-func NewUserService(userRepo *database.UserRepository) *UserService {
+func NewUserService(
+	userRepo *database.UserRepository,
+	referralRepo *database.ReferralRepository,
+	subscriptionService *SubscriptionService,
+	referralRewardDays int,
+	maxReferralRewards int,
+) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:            userRepo,
+		referralRepo:        referralRepo,
+		subscriptionService: subscriptionService,
+		referralRewardDays:  referralRewardDays,
+		maxReferralRewards:  maxReferralRewards,
 	}
 }
 
-// CreateUser cria um novo usuário.
-func (s *UserService) CreateUser(ctx context.Context, email, password string) (*domain.User, error) {
+// CreateUser cria um novo usuário. Com um código de indicação válido, o novo
+// usuário e quem o indicou ganham dias de premium.
+func (s *UserService) CreateUser(ctx context.Context, email, password, referralCode string) (*domain.User, error) {
+	var referrerID uuid.UUID
+	if referralCode != "" {
+		id, err := s.userRepo.GetUserIDByReferralCode(ctx, strings.ToUpper(strings.TrimSpace(referralCode)))
+		if err != nil {
+			return nil, err
+		}
+		referrerID = id
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -30,11 +71,74 @@ func (s *UserService) CreateUser(ctx context.Context, email, password string) (*
 	user := &domain.User{
 		Email:        email,
 		PasswordHash: string(hashedPassword),
+		ReferralCode: newReferralCode(),
 	}
 
 	if err := s.userRepo.CreateUser(ctx, user); err != nil {
 		return nil, err
 	}
 
+	if referrerID != uuid.Nil && s.referralRewardDays > 0 {
+		// O cadastro já foi feito; uma falha na recompensa fica registrada no log
+		// em vez de desfazer a conta
+		if err := s.rewardReferral(ctx, referrerID, user.ID); err != nil {
+			log.Printf("failed to reward referral of user %s by %s: %v", user.ID, referrerID, err)
+		}
+	}
+
 	return user, nil
 }
+
+func (s *UserService) rewardReferral(ctx context.Context, referrerID, referredID uuid.UUID) error {
+	since := time.Now().Add(-referralRewardWindow)
+	created, referrerDays, err := s.referralRepo.CreateReferral(ctx, referrerID, referredID, s.referralRewardDays, s.maxReferralRewards, since)
+	if err != nil || !created {
+		return err
+	}
+
+	payload := map[string]any{
+		"referrer_id": referrerID,
+		"referred_id": referredID,
+		"days":        s.referralRewardDays,
+	}
+	var errs []error
+	if _, err := s.subscriptionService.GrantDays(ctx, referredID, domain.PlanPremium, s.referralRewardDays, domain.SubscriptionEventGrantReferral, payload); err != nil {
+		errs = append(errs, err)
+	}
+	if referrerDays > 0 {
+		if _, err := s.subscriptionService.GrantDays(ctx, referrerID, domain.PlanPremium, referrerDays, domain.SubscriptionEventGrantReferral, payload); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		log.Printf("referrer %s reached the limit of %d referral rewards; only the referred user was rewarded", referrerID, s.maxReferralRewards)
+	}
+	return errors.Join(errs...)
+}
+
+// GetReferralSummary retorna o código de indicação do usuário e o que ele já
+// ganhou com indicações.
+func (s *UserService) GetReferralSummary(ctx context.Context, userID uuid.UUID) (*domain.ReferralSummary, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, days, err := s.referralRepo.GetReferralStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ReferralSummary{
+		Code:          user.ReferralCode,
+		ReferredCount: count,
+		RewardDays:    days,
+	}, nil
+}
+
+func newReferralCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	for i := range b {
+		b[i] = referralCodeAlphabet[int(b[i])%len(referralCodeAlphabet)]
+	}
+	return string(b)
+}
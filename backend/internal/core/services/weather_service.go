@@ -2,55 +2,298 @@ package services
 
 import (
 	"context"
+	"log"
 	"time"
 
 	"github.com/weatherpro/backend/internal/core/domain"
 	"github.com/weatherpro/backend/internal/platform/cache"
 	"github.com/weatherpro/backend/internal/platform/clients/openweathermap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
-	cacheExpiration = 30 * time.Minute
+	// fetchLockTTL limita quanto tempo uma réplica que caiu no meio da busca
+	// segura as demais.
+	fetchLockTTL = 10 * time.Second
+	// fetchWaitTimeout é quanto uma réplica espera pelo resultado da outra
+	// antes de buscar por conta própria.
+	fetchWaitTimeout  = 5 * time.Second
+	fetchPollInterval = 100 * time.Millisecond
+
+	// openWeatherMapCallsPerFetch são as chamadas de cada busca: tempo atual
+	// e previsão.
+	openWeatherMapCallsPerFetch = 2
+	// A partir do nível conserve da cota, as validades são multiplicadas por
+	// quotaTTLFactor e as células ficam quotaWidenSteps níveis mais largas.
+	quotaTTLFactor  = 3
+	quotaWidenSteps = 1
+
+	// weatherBatchParallelism limita as buscas simultâneas de uma consulta em lote.
+	weatherBatchParallelism = 8
 )
 
+// WeatherCacheTTL define a validade das previsões no cache. Depois de Soft a
+// previsão ainda é servida, marcada como desatualizada, enquanto é buscada de
+// novo em segundo plano; depois de Hard ela sai do cache.
+type WeatherCacheTTL struct {
+	Soft time.Duration
+	Hard time.Duration
+}
+
 // WeatherService é um serviço para dados de clima.
 type WeatherService struct {
 	weatherClient *openweathermap.Client
-	weatherCache  *cache.WeatherCache
+	weatherCache  cache.Cache
+	fetchLock     *cache.FetchLock
+	ttl           WeatherCacheTTL
+	quota         *UpstreamQuotaService
+
+	// inflight agrupa buscas simultâneas da mesma chave neste processo.
+	inflight singleflight.Group
 }
 
 // NewWeatherService cria uma nova instância de WeatherService.
-func NewWeatherService(weatherClient *openweathermap.Client, weatherCache *cache.WeatherCache) *WeatherService {
+func NewWeatherService(weatherClient *openweathermap.Client, weatherCache cache.Cache, fetchLock *cache.FetchLock, ttl WeatherCacheTTL, quota *UpstreamQuotaService) *WeatherService {
 	return &WeatherService{
 		weatherClient: weatherClient,
 		weatherCache:  weatherCache,
+		fetchLock:     fetchLock,
+		ttl:           ttl,
+		quota:         quota,
 	}
 }
 
 // GetWeatherData obtém dados de clima para uma latitude e longitude informadas.
-func (s *WeatherService) GetWeatherData(ctx context.Context, lat, lon float64) (*domain.WeatherData, error) {
+// Coordenadas próximas caem na mesma célula do cache e recebem a previsão do
+// centro dela; a resposta informa a resolução efetiva.
+//
+// Perto do limite da API, o serviço aceita previsões mais antigas e células
+// mais largas e, no nível crítico, só requisições com priority (usuários
+// pagantes) disparam novas buscas. Sem cota e sem cache, retorna
+// domain.ErrUpstreamQuotaExhausted.
+func (s *WeatherService) GetWeatherData(ctx context.Context, lat, lon float64, priority bool) (*domain.WeatherData, error) {
+	level := s.quota.Level()
+
+	cells := []cache.Cell{s.weatherCache.Cell(lat, lon)}
+	if domain.QuotaLevelAtLeast(level, domain.QuotaLevelConserve) {
+		// Uma célula mais larga atende mais coordenadas com a mesma busca
+		cells = append(cells, s.weatherCache.CoarseCell(lat, lon, quotaWidenSteps))
+	}
+
 	// Tenta obter os dados primeiro do cache
-	cachedData, err := s.weatherCache.Get(ctx, lat, lon)
+	var stale *domain.WeatherData
+	for _, cell := range cells {
+		cachedData, err := s.weatherCache.Get(ctx, cell)
+		if err != nil {
+			// Registra o erro, mas não falha a requisição
+			// Ainda é possível buscar os dados na API
+			log.Printf("failed to read weather cache: %v", err)
+		}
+		if cachedData == nil {
+			continue
+		}
+		if s.fresh(cachedData, level) {
+			return cachedData, nil
+		}
+		if stale == nil {
+			stale = cachedData
+		}
+	}
+
+	cell := cells[len(cells)-1]
+	allowed := canFetch(level, priority)
+	if stale != nil {
+		// Desatualizado: responde na hora e atualiza em segundo plano. Se a
+		// API estiver fora, a cópia continua servindo até o TTL rígido
+		if allowed {
+			s.refresh(ctx, cell, stale)
+		}
+		return markStale(stale), nil
+	}
+	if !allowed {
+		return nil, domain.ErrUpstreamQuotaExhausted
+	}
+
+	// Requisições simultâneas da mesma chave esperam uma única busca. Ela roda
+	// sem o cancelamento da requisição que a iniciou, já que serve a todas
+	ch := s.inflight.DoChan(cell.Key, func() (any, error) {
+		return s.fetch(context.WithoutCancel(ctx), cell, nil)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*domain.WeatherData), nil
+	}
+}
+
+// GetWeatherBatch obtém as previsões de vários locais, no máximo
+// weatherBatchParallelism por vez. Um local que falha não impede os demais:
+// results[i].Error explica a falha do item i.
+func (s *WeatherService) GetWeatherBatch(ctx context.Context, points []domain.Coordinates, priority bool) []*domain.WeatherBatchResult {
+	results := make([]*domain.WeatherBatchResult, len(points))
+
+	var g errgroup.Group
+	g.SetLimit(weatherBatchParallelism)
+	for i, p := range points {
+		results[i] = &domain.WeatherBatchResult{Lat: p.Lat, Lon: p.Lon}
+		g.Go(func() error {
+			if err := p.Validate(); err != nil {
+				results[i].Error = err.Error()
+				return nil
+			}
+			data, err := s.GetWeatherData(ctx, p.Lat, p.Lon, priority)
+			if err != nil {
+				results[i].Error = err.Error()
+				return nil
+			}
+			results[i].Weather = data
+			return nil
+		})
+	}
+	g.Wait()
+
+	return results
+}
+
+// Cell retorna a célula do cache em que a coordenada cai.
+func (s *WeatherService) Cell(lat, lon float64) cache.Cell {
+	return s.weatherCache.Cell(lat, lon)
+}
+
+// Warm busca de novo a célula se ela não estiver no cache ou se a validade
+// flexível acabar dentro de ahead. Retorna quantas chamadas à API a busca
+// gastou, contando uma busca completa mesmo quando ela foi compartilhada. Perto
+// do limite da API, o aquecimento é suspenso para sobrar cota às requisições.
+func (s *WeatherService) Warm(ctx context.Context, cell cache.Cell, ahead time.Duration) (int, error) {
+	if domain.QuotaLevelAtLeast(s.quota.Level(), domain.QuotaLevelConserve) {
+		return 0, nil
+	}
+
+	cachedData, err := s.weatherCache.Get(ctx, cell)
 	if err != nil {
-		// Registra o erro, mas não falha a requisição
-		// Ainda é possível buscar os dados na API
+		log.Printf("failed to read weather cache: %v", err)
 	}
-	if cachedData != nil {
-		return cachedData, nil
+	if cachedData != nil && time.Since(cachedData.FetchedAt) < s.ttl.Soft-ahead {
+		return 0, nil
 	}
 
-	// Se não estiver no cache, busca na API
-	weatherData, err := s.weatherClient.GetWeatherData(lat, lon)
+	_, err, _ = s.inflight.Do(cell.Key, func() (any, error) {
+		return s.fetch(context.WithoutCancel(ctx), cell, nil)
+	})
+	return openWeatherMapCallsPerFetch, err
+}
+
+// refresh busca a célula de novo sem bloquear quem pediu.
+func (s *WeatherService) refresh(ctx context.Context, cell cache.Cell, stale *domain.WeatherData) {
+	ch := s.inflight.DoChan(cell.Key, func() (any, error) {
+		return s.fetch(context.WithoutCancel(ctx), cell, stale)
+	})
+	go func() {
+		if res := <-ch; res.Err != nil {
+			log.Printf("failed to refresh weather for %s: %v", cell.Key, res.Err)
+		}
+	}()
+}
+
+// fetch busca os dados na API e os salva no cache. Entre réplicas, só quem
+// obtém o lock da chave chama a API; as demais aguardam o resultado no cache.
+// Se a API falhar e houver uma cópia desatualizada, ela é devolvida no lugar.
+func (s *WeatherService) fetch(ctx context.Context, cell cache.Cell, stale *domain.WeatherData) (*domain.WeatherData, error) {
+	release, acquired, err := s.fetchLock.Acquire(ctx, cell.Key, fetchLockTTL)
 	if err != nil {
-		return nil, err
+		log.Printf("failed to acquire fetch lock for %s: %v", cell.Key, err)
+	}
+	if acquired {
+		defer release()
+	} else if err == nil {
+		if data := s.waitForCache(ctx, cell); data != nil {
+			return data, nil
+		}
 	}
 
-	// Salva no cache
-	err = s.weatherCache.Set(ctx, lat, lon, weatherData, cacheExpiration)
+	// Se não estiver no cache, busca na API pelo centro da célula
+	s.quota.Record(ctx, openWeatherMapCallsPerFetch)
+	weatherData, err := s.weatherClient.GetWeatherData(cell.Lat, cell.Lon)
 	if err != nil {
+		if stale != nil {
+			log.Printf("failed to fetch weather for %s, serving stale data: %v", cell.Key, err)
+			return markStale(stale), nil
+		}
+		return nil, err
+	}
+	resolution := cell.Resolution
+	weatherData.Resolution = &resolution
+	weatherData.FetchedAt = time.Now()
+
+	// Salva no cache até o TTL rígido; a validade fica a cargo de FetchedAt
+	if err := s.weatherCache.Set(ctx, cell, weatherData, s.hardTTL(s.quota.Level())); err != nil {
 		// Registra o erro, mas não falha a requisição
 		// Os dados continuam válidos
+		log.Printf("failed to write weather cache: %v", err)
 	}
 
 	return weatherData, nil
 }
+
+// waitForCache consulta o cache até outra réplica gravar uma previsão válida
+// para a célula ou o tempo de espera acabar.
+func (s *WeatherService) waitForCache(ctx context.Context, cell cache.Cell) *domain.WeatherData {
+	ticker := time.NewTicker(fetchPollInterval)
+	defer ticker.Stop()
+	timeout := time.After(fetchWaitTimeout)
+
+	for {
+		select {
+		case <-timeout:
+			return nil
+		case <-ticker.C:
+			if data, err := s.weatherCache.Get(ctx, cell); err == nil && data != nil && s.fresh(data, s.quota.Level()) {
+				return data
+			}
+		}
+	}
+}
+
+// fresh indica se a previsão ainda está dentro do TTL flexível, estendido
+// quando é preciso economizar cota.
+func (s *WeatherService) fresh(data *domain.WeatherData, level string) bool {
+	soft := s.ttl.Soft
+	if domain.QuotaLevelAtLeast(level, domain.QuotaLevelConserve) {
+		soft *= quotaTTLFactor
+	}
+	return time.Since(data.FetchedAt) < soft
+}
+
+// hardTTL é por quanto tempo uma nova previsão fica no cache.
+func (s *WeatherService) hardTTL(level string) time.Duration {
+	if domain.QuotaLevelAtLeast(level, domain.QuotaLevelConserve) {
+		return s.ttl.Hard * quotaTTLFactor
+	}
+	return s.ttl.Hard
+}
+
+// canFetch indica se o nível de consumo da cota ainda permite buscar na API.
+func canFetch(level string, priority bool) bool {
+	switch level {
+	case domain.QuotaLevelExhausted:
+		return false
+	case domain.QuotaLevelCritical:
+		return priority
+	default:
+		return true
+	}
+}
+
+// markStale devolve uma cópia marcada como desatualizada, sem alterar a
+// instância compartilhada pelo cache em memória.
+func markStale(data *domain.WeatherData) *domain.WeatherData {
+	stale := *data
+	stale.Stale = true
+	return &stale
+}
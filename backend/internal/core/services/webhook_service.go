@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weatherpro/backend/internal/core/domain"
+	"github.com/weatherpro/backend/internal/platform/database"
+	"github.com/weatherpro/backend/internal/platform/safehttp"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// Desativa o endpoint após esta quantidade de entregas falhas seguidas.
+	maxWebhookConsecutiveFailures = 10
+	// Máximo de entregas pendentes por endpoint; além dele, novos eventos são
+	// descartados até a fila andar.
+	maxPendingWebhookDeliveries = 1000
+	// Entregas reservadas por rodada do despachante e enviadas em paralelo.
+	webhookDeliveryBatchSize   = 100
+	webhookDeliveryParallelism = 8
+	// Por quanto tempo uma entrega reservada fica fora do alcance das outras
+	// réplicas; deve cobrir o envio do lote inteiro.
+	webhookDeliveryLease = 5 * time.Minute
+
+	webhookSignatureHeader = "X-WeatherPro-Signature"
+	webhookTimestampHeader = "X-WeatherPro-Timestamp"
+	webhookEventHeader     = "X-WeatherPro-Event"
+	webhookDeliveryHeader  = "X-WeatherPro-Delivery"
+)
+
+// Intervalos de espera antes de cada tentativa de entrega.
+var webhookRetryDelays = []time.Duration{0, 10 * time.Second, time.Minute, 5 * time.Minute, 30 * time.Minute}
+
+// WebhookService gerencia os endpoints de webhook dos usuários e entrega
+// eventos assinados com HMAC-SHA256.
+type WebhookService struct {
+	repo         *database.WebhookEndpointRepository
+	deliveryRepo *database.WebhookDeliveryRepository
+	httpClient   *http.Client
+}
+
+// NewWebhookService cria uma nova instância de WebhookService.
+func NewWebhookService(repo *database.WebhookEndpointRepository, deliveryRepo *database.WebhookDeliveryRepository, httpClient *http.Client) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+		httpClient:   httpClient,
+	}
+}
+
+// CreateEndpoint cadastra um endpoint e gera o segredo usado nas assinaturas.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	if err := validateWebhookURL(endpoint.URL); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	endpoint.Secret = "whsec_" + hex.EncodeToString(secret)
+	endpoint.IsEnabled = true
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+
+	return s.repo.CreateWebhookEndpoint(ctx, endpoint)
+}
+
+// GetEndpointsByUserID lista os endpoints de um usuário, sem os segredos.
+func (s *WebhookService) GetEndpointsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.WebhookEndpoint, error) {
+	endpoints, err := s.repo.GetWebhookEndpointsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// UpdateEndpoint atualiza a URL, os filtros de evento e o estado do endpoint.
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	if err := validateWebhookURL(endpoint.URL); err != nil {
+		return err
+	}
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+	return s.repo.UpdateWebhookEndpoint(ctx, endpoint)
+}
+
+// DeleteEndpoint remove um endpoint do usuário.
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.DeleteWebhookEndpoint(ctx, userID, id)
+}
+
+// SendTestEvent entrega um evento de teste ao endpoint, em uma única tentativa,
+// e retorna o resultado. Testes não contam para a desativação automática.
+func (s *WebhookService) SendTestEvent(ctx context.Context, userID, id uuid.UUID) (*domain.WebhookDeliveryResult, error) {
+	endpoint, err := s.repo.GetWebhookEndpointByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := newWebhookEvent(domain.WebhookEventTest, map[string]string{
+		"message": "Este é um evento de teste do WeatherPro.",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.deliverOnce(ctx, endpoint, event), nil
+}
+
+// Publish agenda a entrega do evento a todos os endpoints ativos do usuário
+// inscritos no tipo do evento. As entregas ficam no banco e são feitas, com
+// novas tentativas, por RunDeliveryDispatcher.
+func (s *WebhookService) Publish(ctx context.Context, userID uuid.UUID, eventType string, data any) {
+	endpoints, err := s.repo.GetEnabledWebhookEndpointsByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("failed to list webhook endpoints for user %s: %v", userID, err)
+		return
+	}
+
+	var endpointIDs []uuid.UUID
+	for _, endpoint := range endpoints {
+		if endpoint.Accepts(eventType) {
+			endpointIDs = append(endpointIDs, endpoint.ID)
+		}
+	}
+	if len(endpointIDs) == 0 {
+		return
+	}
+
+	// O evento é serializado agora: data pode continuar sendo alterado pelo
+	// chamador depois que Publish retorna
+	event, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		log.Printf("failed to encode %s webhook event for user %s: %v", eventType, userID, err)
+		return
+	}
+	scheduled, err := s.deliveryRepo.CreateWebhookDeliveries(ctx, event, endpointIDs, maxPendingWebhookDeliveries)
+	if err != nil {
+		log.Printf("failed to schedule %s webhook event for user %s: %v", eventType, userID, err)
+		return
+	}
+	if dropped := int64(len(endpointIDs)) - scheduled; dropped > 0 {
+		log.Printf("dropped %s webhook event for %d endpoints of user %s with full delivery queues", eventType, dropped, userID)
+	}
+}
+
+// RunDeliveryDispatcher envia periodicamente as entregas de webhook pendentes
+// cujo horário chegou, até que o contexto seja cancelado. As entregas são
+// reservadas no banco, então várias réplicas podem rodar o despachante.
+func (s *WebhookService) RunDeliveryDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDeliveries(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebhookService) dispatchDeliveries(ctx context.Context) {
+	deliveries, err := s.deliveryRepo.ClaimDueWebhookDeliveries(ctx, time.Now(), webhookDeliveryLease, webhookDeliveryBatchSize)
+	if err != nil {
+		log.Printf("failed to claim webhook deliveries: %v", err)
+		return
+	}
+
+	var g errgroup.Group
+	g.SetLimit(webhookDeliveryParallelism)
+	for _, delivery := range deliveries {
+		g.Go(func() error {
+			s.attemptDelivery(ctx, delivery)
+			return nil
+		})
+	}
+	g.Wait()
+}
+
+// attemptDelivery faz uma tentativa de entrega e agenda a próxima ou, quando
+// as tentativas acabam, registra a falha no endpoint.
+func (s *WebhookService) attemptDelivery(ctx context.Context, delivery *domain.WebhookDelivery) {
+	endpoint := delivery.Endpoint
+	result := s.deliverOnce(ctx, endpoint, delivery.Event)
+	if ctx.Err() != nil {
+		// Encerramento: a entrega volta a ficar disponível quando o lease acabar
+		return
+	}
+
+	if result.Success {
+		if err := s.deliveryRepo.DeleteWebhookDelivery(ctx, delivery.ID); err != nil {
+			log.Printf("failed to remove webhook delivery %s: %v", delivery.ID, err)
+		}
+		if err := s.repo.RecordSuccess(ctx, endpoint.ID); err != nil {
+			log.Printf("failed to record webhook success for endpoint %s: %v", endpoint.ID, err)
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts < len(webhookRetryDelays) {
+		next := time.Now().Add(webhookRetryDelays[attempts])
+		if err := s.deliveryRepo.RescheduleWebhookDelivery(ctx, delivery.ID, attempts, next, result.Error); err != nil {
+			log.Printf("failed to reschedule webhook delivery %s: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	if err := s.deliveryRepo.DeleteWebhookDelivery(ctx, delivery.ID); err != nil {
+		log.Printf("failed to remove webhook delivery %s: %v", delivery.ID, err)
+	}
+	disabled, err := s.repo.RecordFailure(ctx, endpoint.ID, result.Error, maxWebhookConsecutiveFailures)
+	if err != nil {
+		log.Printf("failed to record webhook failure for endpoint %s: %v", endpoint.ID, err)
+		return
+	}
+	if disabled {
+		log.Printf("webhook endpoint %s disabled after %d consecutive failures", endpoint.ID, maxWebhookConsecutiveFailures)
+	}
+}
+
+// deliverOnce envia o evento assinado. A assinatura é o HMAC-SHA256, com o
+// segredo do endpoint, de "<timestamp>.<corpo>"; o receptor deve rejeitar
+// timestamps antigos para evitar replays.
+func (s *WebhookService) deliverOnce(ctx context.Context, endpoint *domain.WebhookEndpoint, event *domain.WebhookEvent) *domain.WebhookDeliveryResult {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return &domain.WebhookDeliveryResult{Error: err.Error()}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return &domain.WebhookDeliveryResult{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, "v1="+SignWebhookPayload(endpoint.Secret, timestamp, body))
+	req.Header.Set(webhookEventHeader, event.Type)
+	req.Header.Set(webhookDeliveryHeader, event.ID.String())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return &domain.WebhookDeliveryResult{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &domain.WebhookDeliveryResult{
+			StatusCode: resp.StatusCode,
+			Error:      fmt.Sprintf("endpoint responded with status code %d", resp.StatusCode),
+		}
+	}
+	return &domain.WebhookDeliveryResult{Success: true, StatusCode: resp.StatusCode}
+}
+
+// SignWebhookPayload calcula a assinatura hexadecimal HMAC-SHA256 de "<timestamp>.<corpo>".
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookEvent(eventType string, data any) (*domain.WebhookEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      payload,
+	}, nil
+}
+
+// validateWebhookURL rejeita de antemão URLs que não são HTTPS ou que apontam
+// para a própria máquina ou a rede interna. Nomes que resolvem para endereços
+// internos são barrados na conexão, pelo cliente de safehttp.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("%w: url must be an absolute https URL", domain.ErrInvalidWebhookEndpoint)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") || strings.HasSuffix(host, ".local") {
+		return fmt.Errorf("%w: url must point to a public host", domain.ErrInvalidWebhookEndpoint)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !safehttp.IsPublicAddr(addr) {
+		return fmt.Errorf("%w: url must point to a public host", domain.ErrInvalidWebhookEndpoint)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AlertDedup evita que o mesmo alerta seja enviado repetidamente, mesmo com
+// várias réplicas do backend avaliando os mesmos locais.
+type AlertDedup struct {
+	client *redis.Client
+}
+
+// NewAlertDedup cria uma nova instância de AlertDedup.
+func NewAlertDedup(client *redis.Client) *AlertDedup {
+	return &AlertDedup{
+		client: client,
+	}
+}
+
+// Acquire reserva a chave pelo período informado. Retorna false se ela já
+// estava reservada, ou seja, se o alerta já foi enviado recentemente.
+func (d *AlertDedup) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, "alert:"+key, 1, ttl).Result()
+}
+
+// Active indica, para cada chave, se ela ainda está reservada, ou seja, se o
+// alerta foi enviado e continua em vigor.
+func (d *AlertDedup) Active(ctx context.Context, keys ...string) ([]bool, error) {
+	pipe := d.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Exists(ctx, "alert:"+key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	active := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		active[i] = cmd.Val() > 0
+	}
+	return active, nil
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// Cache é um cache de dados de clima por célula de coordenadas. As
+// implementações podem ter uma ou mais camadas; quem usa não precisa saber
+// quantas.
+type Cache interface {
+	// Cell retorna a célula da coordenada; coordenadas da mesma célula
+	// compartilham a entrada.
+	Cell(lat, lon float64) Cell
+	// CoarseCell retorna uma célula steps níveis mais larga, usada para
+	// economizar chamadas à API.
+	CoarseCell(lat, lon float64, steps int) Cell
+	// Get retorna nil, sem erro, quando a célula não está no cache.
+	Get(ctx context.Context, cell Cell) (*domain.WeatherData, error)
+	Set(ctx context.Context, cell Cell, data *domain.WeatherData, expiration time.Duration) error
+	Delete(ctx context.Context, cell Cell) error
+}
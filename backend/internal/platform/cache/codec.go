@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// O primeiro byte de cada valor gravado identifica o formato: o nibble baixo é
+// o serializador e o alto, a compressão. Entradas antigas, gravadas em JSON
+// puro, começam com '{' e continuam legíveis.
+const (
+	serializerJSON byte = 0x01
+	serializerGob  byte = 0x02
+
+	compressionNone  byte = 0x00
+	compressionFlate byte = 0x10
+
+	legacyJSONPrefix byte = '{'
+)
+
+// serializer converte previsões de e para bytes.
+type serializer interface {
+	marshal(w io.Writer, data *domain.WeatherData) error
+	unmarshal(r io.Reader, data *domain.WeatherData) error
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) marshal(w io.Writer, data *domain.WeatherData) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+func (jsonSerializer) unmarshal(r io.Reader, data *domain.WeatherData) error {
+	return json.NewDecoder(r).Decode(data)
+}
+
+type gobSerializer struct{}
+
+func (gobSerializer) marshal(w io.Writer, data *domain.WeatherData) error {
+	return gob.NewEncoder(w).Encode(data)
+}
+
+func (gobSerializer) unmarshal(r io.Reader, data *domain.WeatherData) error {
+	return gob.NewDecoder(r).Decode(data)
+}
+
+var serializers = map[byte]serializer{
+	serializerJSON: jsonSerializer{},
+	serializerGob:  gobSerializer{},
+}
+
+var serializerNames = map[string]byte{
+	"json": serializerJSON,
+	"gob":  serializerGob,
+}
+
+var compressionNames = map[string]byte{
+	"":      compressionNone,
+	"flate": compressionFlate,
+}
+
+// Os compressores alocam buffers grandes; reaproveitá-los evita esse custo a
+// cada escrita.
+var (
+	flateWriters = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	}}
+	flateReaders = sync.Pool{New: func() any {
+		return flate.NewReader(nil)
+	}}
+)
+
+// Codec grava previsões no formato configurado e lê qualquer formato conhecido.
+type Codec struct {
+	format byte
+}
+
+// NewCodec cria um codec a partir do nome do formato: "json" ou "gob",
+// opcionalmente seguido de "+flate" para comprimir.
+func NewCodec(name string) (*Codec, error) {
+	serializerName, compressionName, _ := strings.Cut(name, "+")
+	s, okS := serializerNames[serializerName]
+	c, okC := compressionNames[compressionName]
+	if !okS || !okC {
+		return nil, fmt.Errorf("unknown cache codec %q", name)
+	}
+	return &Codec{format: s | c}, nil
+}
+
+// Encode serializa a previsão precedida do byte de formato.
+func (c *Codec) Encode(data *domain.WeatherData) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(c.format)
+
+	s := serializers[c.format&0x0f]
+	if c.format&0xf0 == compressionNone {
+		if err := s.marshal(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	fw := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(fw)
+	fw.Reset(&buf)
+	if err := s.marshal(fw, data); err != nil {
+		return nil, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode lê uma previsão em qualquer formato conhecido, inclusive o JSON sem
+// cabeçalho das versões anteriores.
+func (c *Codec) Decode(raw []byte, data *domain.WeatherData) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("empty cache entry")
+	}
+	if raw[0] == legacyJSONPrefix {
+		return json.Unmarshal(raw, data)
+	}
+
+	format := raw[0]
+	s, ok := serializers[format&0x0f]
+	if !ok {
+		return fmt.Errorf("unknown cache entry format 0x%02x", format)
+	}
+
+	body := bytes.NewReader(raw[1:])
+	switch format & 0xf0 {
+	case compressionNone:
+		return s.unmarshal(body, data)
+	case compressionFlate:
+		fr := flateReaders.Get().(io.ReadCloser)
+		defer flateReaders.Put(fr)
+		if err := fr.(flate.Resetter).Reset(body, nil); err != nil {
+			return err
+		}
+		return s.unmarshal(fr, data)
+	default:
+		return fmt.Errorf("unknown cache entry format 0x%02x", format)
+	}
+}
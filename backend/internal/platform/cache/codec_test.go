@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+var codecNames = []string{"json", "gob", "json+flate", "gob+flate"}
+
+// sampleWeatherData monta uma previsão com 48 horas e 8 dias, o maior
+// formato que o app consome.
+func sampleWeatherData() *domain.WeatherData {
+	now := time.Date(2026, time.March, 14, 9, 0, 0, 0, time.UTC)
+	conditions := []domain.Weather{{ID: 500, Main: "Rain", Description: "chuva leve", Icon: "10d"}}
+
+	data := &domain.WeatherData{
+		Lat:            -23.55194091796875,
+		Lon:            -46.6314697265625,
+		Timezone:       "America/Sao_Paulo",
+		TimezoneOffset: -10800,
+		Current: domain.CurrentWeather{
+			Dt: now.Unix(), Sunrise: now.Add(-6 * time.Hour).Unix(), Sunset: now.Add(6 * time.Hour).Unix(),
+			Temp: 22.4, FeelsLike: 22.9, Pressure: 1014, Humidity: 78, DewPoint: 18.3, UVI: 4.2,
+			Clouds: 75, Visibility: 10000, WindSpeed: 3.6, WindDeg: 140, Weather: conditions,
+		},
+		Resolution: &domain.Resolution{
+			Method: domain.ResolutionGeohash, Precision: 6, Cell: "6gyf4b",
+			Lat: -23.55194091796875, Lon: -46.6314697265625, WidthMeters: 1121, HeightMeters: 611,
+		},
+		FetchedAt: now,
+	}
+
+	for i := range 48 {
+		data.Hourly = append(data.Hourly, domain.HourlyForecast{
+			Dt: now.Add(time.Duration(i) * time.Hour).Unix(), Temp: 20 + float64(i%10)*0.7, FeelsLike: 20.5 + float64(i%10)*0.7,
+			Pressure: 1012 + i%5, Humidity: 60 + i%30, DewPoint: 16.1, UVI: float64(i%12) * 0.8, Clouds: i % 100,
+			Visibility: 10000, WindSpeed: 2.1 + float64(i%7)*0.3, WindDeg: (i * 15) % 360, WindGust: 5.2,
+			Weather: conditions, Pop: float64(i%10) / 10,
+		})
+	}
+	for i := range 8 {
+		day := now.AddDate(0, 0, i)
+		data.Daily = append(data.Daily, domain.DailyForecast{
+			Dt: day.Unix(), Sunrise: day.Add(-6 * time.Hour).Unix(), Sunset: day.Add(6 * time.Hour).Unix(),
+			Moonrise: day.Add(-4 * time.Hour).Unix(), Moonset: day.Add(8 * time.Hour).Unix(), MoonPhase: 0.25,
+			Temp:      domain.Temp{Day: 25.1, Min: 17.3, Max: 27.8, Night: 18.9, Eve: 23.4, Morn: 18.2},
+			FeelsLike: domain.FeelsLike{Day: 25.6, Night: 19.1, Eve: 23.9, Morn: 18.4},
+			Pressure:  1013, Humidity: 70, DewPoint: 17.2, WindSpeed: 4.1, WindDeg: 120, WindGust: 8.3,
+			Weather: conditions, Clouds: 60, Pop: 0.4, UVI: 9.1,
+		})
+	}
+	return data
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	want := sampleWeatherData()
+	for _, name := range codecNames {
+		t.Run(name, func(t *testing.T) {
+			codec, err := NewCodec(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := codec.Encode(want)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			var got domain.WeatherData
+			if err := codec.Decode(raw, &got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(&got, want) {
+				t.Fatalf("Decode() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// Qualquer codec lê o que os outros gravaram, para que trocar CACHE_CODEC não
+// invalide o cache.
+func TestCodecDecodesEveryFormat(t *testing.T) {
+	want := sampleWeatherData()
+	for _, writer := range codecNames {
+		w, err := NewCodec(writer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := w.Encode(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, reader := range codecNames {
+			r, err := NewCodec(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got domain.WeatherData
+			if err := r.Decode(raw, &got); err != nil {
+				t.Errorf("%s reading %s: Decode() error = %v", reader, writer, err)
+				continue
+			}
+			if !reflect.DeepEqual(&got, want) {
+				t.Errorf("%s reading %s: decoded data differs", reader, writer)
+			}
+		}
+	}
+}
+
+func TestCodecDecodesLegacyJSON(t *testing.T) {
+	want := sampleWeatherData()
+	raw, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != legacyJSONPrefix {
+		t.Fatalf("legacy entry starts with %q, want %q", raw[0], legacyJSONPrefix)
+	}
+
+	for _, name := range codecNames {
+		t.Run(name, func(t *testing.T) {
+			codec, err := NewCodec(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got domain.WeatherData
+			if err := codec.Decode(raw, &got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(&got, want) {
+				t.Fatalf("Decode() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeRejectsInvalidEntries(t *testing.T) {
+	codec, err := NewCodec("gob+flate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{name: "empty", raw: nil},
+		{name: "unknown serializer", raw: []byte{0x0f, 0x00}},
+		{name: "unknown compression", raw: []byte{serializerJSON | 0x70, '{', '}'}},
+		{name: "truncated gob", raw: []byte{serializerGob, 0x01}},
+		{name: "corrupt flate", raw: []byte{serializerJSON | compressionFlate, 0xff, 0xff, 0xff}},
+		{name: "truncated legacy JSON", raw: []byte(`{"lat":`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.WeatherData
+			if err := codec.Decode(tt.raw, &got); err == nil {
+				t.Fatal("Decode() accepted an invalid entry")
+			}
+		})
+	}
+}
+
+func TestNewCodecRejectsUnknownFormats(t *testing.T) {
+	for _, name := range []string{"", "xml", "json+gzip", "+flate"} {
+		if _, err := NewCodec(name); err == nil {
+			t.Errorf("NewCodec(%q) accepted an unknown format", name)
+		}
+	}
+}
+
+func BenchmarkCodecEncode(b *testing.B) {
+	data := sampleWeatherData()
+	for _, name := range codecNames {
+		b.Run(name, func(b *testing.B) {
+			codec, err := NewCodec(name)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var size int
+			b.ReportAllocs()
+			for b.Loop() {
+				raw, err := codec.Encode(data)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(raw)
+			}
+			b.ReportMetric(float64(size), "bytes/entry")
+		})
+	}
+}
+
+func BenchmarkCodecDecode(b *testing.B) {
+	data := sampleWeatherData()
+	for _, name := range codecNames {
+		b.Run(name, func(b *testing.B) {
+			codec, err := NewCodec(name)
+			if err != nil {
+				b.Fatal(err)
+			}
+			raw, err := codec.Encode(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				var out domain.WeatherData
+				if err := codec.Decode(raw, &out); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(raw)), "bytes/entry")
+		})
+	}
+}
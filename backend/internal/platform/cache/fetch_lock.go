@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseScript só apaga o lock se ele ainda pertencer a quem o adquiriu, para
+// não liberar um lock que expirou e foi tomado por outra réplica.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// FetchLock é um lock curto no Valkey que garante que só uma réplica busque o
+// mesmo dado na API externa por vez.
+type FetchLock struct {
+	client *redis.Client
+}
+
+// NewFetchLock cria uma nova instância de FetchLock.
+func NewFetchLock(client *redis.Client) *FetchLock {
+	return &FetchLock{
+		client: client,
+	}
+}
+
+// Acquire tenta reservar a chave por ttl. Quando consegue, retorna a função
+// que libera o lock; quando outra réplica já o detém, retorna false.
+func (l *FetchLock) Acquire(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	lockKey := "lock:" + key
+	token := uuid.NewString()
+
+	acquired, err := l.client.SetNX(ctx, lockKey, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	release := func() {
+		// A liberação não deve depender do contexto da requisição que buscou
+		releaseScript.Run(context.Background(), l.client, []string{lockKey}, token)
+	}
+	return release, true, nil
+}
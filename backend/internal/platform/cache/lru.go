@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU é um cache em memória limitado por quantidade de entradas, com
+// expiração por entrada. As entradas menos usadas são descartadas primeiro.
+type LRU[V any] struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU cria um LRU com a capacidade informada.
+func NewLRU[V any](capacity int) *LRU[V] {
+	return &LRU[V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get retorna o valor da chave se ele existir e não tiver expirado.
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[V])
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set grava o valor com validade ttl, descartando a entrada menos usada se o
+// cache estiver cheio.
+func (c *LRU[V]) Set(key string, value V, ttl time.Duration) {
+	if c.capacity <= 0 || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete remove a chave.
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Purge remove todas as entradas.
+func (c *LRU[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+// Len retorna a quantidade de entradas, incluindo as expiradas ainda não descartadas.
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+const (
+	geohashAlphabet     = "0123456789bcdefghjkmnpqrstuvwxyz"
+	maxGeohashPrecision = 12
+	metersPerDegree     = 111320.0
+)
+
+// Cell é a célula do cache em que uma coordenada caiu.
+type Cell struct {
+	// Key identifica a célula, sem o prefixo do cache.
+	Key        string
+	Lat        float64
+	Lon        float64
+	Resolution domain.Resolution
+}
+
+// Quantizer agrupa coordenadas próximas em células, para que o ruído do GPS
+// não gere uma entrada de cache por requisição.
+type Quantizer struct {
+	method      string
+	precision   int
+	gridDegrees float64
+}
+
+// NewQuantizer cria um quantizador por geohash com precision caracteres ou
+// por grade regular com células de gridDegrees graus.
+func NewQuantizer(method string, precision int, gridDegrees float64) (*Quantizer, error) {
+	switch method {
+	case domain.ResolutionGeohash:
+		if precision < 1 || precision > maxGeohashPrecision {
+			return nil, fmt.Errorf("geohash precision must be between 1 and %d", maxGeohashPrecision)
+		}
+	case domain.ResolutionGrid:
+		if gridDegrees <= 0 || gridDegrees > 10 {
+			return nil, fmt.Errorf("grid degrees must be greater than 0 and at most 10")
+		}
+	default:
+		return nil, fmt.Errorf("unknown cache quantization method %q", method)
+	}
+	return &Quantizer{method: method, precision: precision, gridDegrees: gridDegrees}, nil
+}
+
+// Widen retorna um quantizador com células steps níveis mais largas: um
+// caractere a menos de geohash ou o dobro do lado da grade por nível.
+func (q *Quantizer) Widen(steps int) *Quantizer {
+	wide := *q
+	wide.precision = max(1, q.precision-steps)
+	wide.gridDegrees = min(10, q.gridDegrees*math.Pow(2, float64(steps)))
+	return &wide
+}
+
+// Cell retorna a célula da coordenada.
+func (q *Quantizer) Cell(lat, lon float64) Cell {
+	lat = math.Max(-90, math.Min(90, lat))
+	lon = math.Max(-180, math.Min(180, lon))
+
+	if q.method == domain.ResolutionGrid {
+		return q.gridCell(lat, lon)
+	}
+	return q.geohashCell(lat, lon)
+}
+
+func (q *Quantizer) geohashCell(lat, lon float64) Cell {
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+
+	var hash strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for hash.Len() < q.precision {
+		// Os bits alternam entre longitude e latitude, começando pela longitude
+		if even {
+			mid := (lonMin + lonMax) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				lonMin = mid
+			} else {
+				ch <<= 1
+				lonMax = mid
+			}
+		} else {
+			mid := (latMin + latMax) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latMin = mid
+			} else {
+				ch <<= 1
+				latMax = mid
+			}
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	key := hash.String()
+	return newCell("gh"+strconv.Itoa(q.precision)+":"+key, latMin, latMax, lonMin, lonMax, domain.Resolution{
+		Method:    domain.ResolutionGeohash,
+		Precision: q.precision,
+		Cell:      key,
+	})
+}
+
+func (q *Quantizer) gridCell(lat, lon float64) Cell {
+	// O polo norte e o antimeridiano caem na última linha e coluna da grade
+	row := min(int(math.Floor((lat+90)/q.gridDegrees)), int(math.Ceil(180/q.gridDegrees))-1)
+	col := min(int(math.Floor((lon+180)/q.gridDegrees)), int(math.Ceil(360/q.gridDegrees))-1)
+	latMin := float64(row)*q.gridDegrees - 90
+	lonMin := float64(col)*q.gridDegrees - 180
+
+	key := strconv.Itoa(row) + ":" + strconv.Itoa(col)
+	return newCell("grid"+formatFloat(q.gridDegrees)+":"+key, latMin, math.Min(latMin+q.gridDegrees, 90), lonMin, math.Min(lonMin+q.gridDegrees, 180), domain.Resolution{
+		Method:      domain.ResolutionGrid,
+		GridDegrees: q.gridDegrees,
+		Cell:        key,
+	})
+}
+
+// CellCenter decodifica o centro de uma célula a partir da sua chave, em
+// qualquer um dos métodos, independentemente da configuração atual.
+func CellCenter(cellKey string) (lat, lon float64, ok bool) {
+	prefix, rest, found := strings.Cut(cellKey, ":")
+	if !found {
+		return 0, 0, false
+	}
+
+	switch {
+	case strings.HasPrefix(prefix, "gh"):
+		latMin, latMax, lonMin, lonMax, ok := decodeGeohash(rest)
+		return (latMin + latMax) / 2, (lonMin + lonMax) / 2, ok
+	case strings.HasPrefix(prefix, "grid"):
+		degrees, err := strconv.ParseFloat(strings.TrimPrefix(prefix, "grid"), 64)
+		rowStr, colStr, found := strings.Cut(rest, ":")
+		if err != nil || degrees <= 0 || !found {
+			return 0, 0, false
+		}
+		row, errRow := strconv.Atoi(rowStr)
+		col, errCol := strconv.Atoi(colStr)
+		if errRow != nil || errCol != nil {
+			return 0, 0, false
+		}
+		latMin := float64(row)*degrees - 90
+		lonMin := float64(col)*degrees - 180
+		return (latMin + math.Min(latMin+degrees, 90)) / 2, (lonMin + math.Min(lonMin+degrees, 180)) / 2, true
+	}
+	return 0, 0, false
+}
+
+func decodeGeohash(hash string) (latMin, latMax, lonMin, lonMax float64, ok bool) {
+	latMin, latMax = -90, 90
+	lonMin, lonMax = -180, 180
+	if hash == "" {
+		return latMin, latMax, lonMin, lonMax, false
+	}
+
+	even := true
+	for i := 0; i < len(hash); i++ {
+		idx := strings.IndexByte(geohashAlphabet, hash[i])
+		if idx < 0 {
+			return latMin, latMax, lonMin, lonMax, false
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx>>bit&1 == 1
+			if even {
+				mid := (lonMin + lonMax) / 2
+				if set {
+					lonMin = mid
+				} else {
+					lonMax = mid
+				}
+			} else {
+				mid := (latMin + latMax) / 2
+				if set {
+					latMin = mid
+				} else {
+					latMax = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return latMin, latMax, lonMin, lonMax, true
+}
+
+func newCell(key string, latMin, latMax, lonMin, lonMax float64, res domain.Resolution) Cell {
+	lat := (latMin + latMax) / 2
+	lon := (lonMin + lonMax) / 2
+
+	res.Lat = lat
+	res.Lon = lon
+	res.HeightMeters = math.Round((latMax - latMin) * metersPerDegree)
+	res.WidthMeters = math.Round((lonMax - lonMin) * metersPerDegree * math.Cos(lat*math.Pi/180))
+	return Cell{Key: key, Lat: lat, Lon: lon, Resolution: res}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"math"
+	"testing"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+func mustQuantizer(t *testing.T, method string, precision int, gridDegrees float64) *Quantizer {
+	t.Helper()
+	q, err := NewQuantizer(method, precision, gridDegrees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestQuantizerGeohashCell(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision int
+		lat, lon  float64
+		wantKey   string
+	}{
+		// Exemplos de referência do geohash
+		{name: "jutland", precision: 11, lat: 57.64911, lon: 10.40744, wantKey: "gh11:u4pruydqqvj"},
+		{name: "jutland coarse", precision: 5, lat: 57.64911, lon: 10.40744, wantKey: "gh5:u4pru"},
+		{name: "são paulo", precision: 6, lat: -23.5505, lon: -46.6333, wantKey: "gh6:6gyf4b"},
+		{name: "origin", precision: 1, lat: 0, lon: 0, wantKey: "gh1:s"},
+		{name: "south-west corner", precision: 3, lat: -90, lon: -180, wantKey: "gh3:000"},
+		{name: "north-east corner", precision: 3, lat: 90, lon: 180, wantKey: "gh3:zzz"},
+		{name: "out of range is clamped", precision: 3, lat: 95, lon: 200, wantKey: "gh3:zzz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := mustQuantizer(t, domain.ResolutionGeohash, tt.precision, 0).Cell(tt.lat, tt.lon)
+			if cell.Key != tt.wantKey {
+				t.Fatalf("Cell().Key = %q, want %q", cell.Key, tt.wantKey)
+			}
+			if cell.Resolution.Method != domain.ResolutionGeohash || cell.Resolution.Precision != tt.precision {
+				t.Fatalf("Cell().Resolution = %+v", cell.Resolution)
+			}
+		})
+	}
+}
+
+func TestQuantizerGridCell(t *testing.T) {
+	tests := []struct {
+		name     string
+		degrees  float64
+		lat, lon float64
+		wantKey  string
+		wantLat  float64
+		wantLon  float64
+	}{
+		{name: "são paulo", degrees: 0.5, lat: -23.5505, lon: -46.6333, wantKey: "grid0.5:132:266", wantLat: -23.75, wantLon: -46.75},
+		{name: "south-west corner", degrees: 1, lat: -90, lon: -180, wantKey: "grid1:0:0", wantLat: -89.5, wantLon: -179.5},
+		{name: "north pole and antimeridian", degrees: 1, lat: 90, lon: 180, wantKey: "grid1:179:359", wantLat: 89.5, wantLon: 179.5},
+		{name: "grid that does not divide the globe", degrees: 7, lat: 90, lon: 180, wantKey: "grid7:25:51", wantLat: 87.5, wantLon: 178.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := mustQuantizer(t, domain.ResolutionGrid, 0, tt.degrees).Cell(tt.lat, tt.lon)
+			if cell.Key != tt.wantKey {
+				t.Fatalf("Cell().Key = %q, want %q", cell.Key, tt.wantKey)
+			}
+			if math.Abs(cell.Lat-tt.wantLat) > 1e-9 || math.Abs(cell.Lon-tt.wantLon) > 1e-9 {
+				t.Fatalf("Cell() center = %v, %v, want %v, %v", cell.Lat, cell.Lon, tt.wantLat, tt.wantLon)
+			}
+		})
+	}
+}
+
+// O ruído do GPS não pode gerar uma entrada de cache por requisição.
+func TestQuantizerGroupsNearbyPoints(t *testing.T) {
+	for _, q := range []*Quantizer{
+		mustQuantizer(t, domain.ResolutionGeohash, 5, 0),
+		mustQuantizer(t, domain.ResolutionGrid, 0, 0.1),
+	} {
+		a := q.Cell(-23.5505, -46.6333)
+		b := q.Cell(-23.5506, -46.6331)
+		if a.Key != b.Key {
+			t.Errorf("%s: nearby points in cells %q and %q", q.method, a.Key, b.Key)
+		}
+	}
+}
+
+func TestCellCenterMatchesCell(t *testing.T) {
+	points := [][2]float64{{-23.5505, -46.6333}, {57.64911, 10.40744}, {0, 0}, {90, 180}, {-90, -180}, {-33.8688, 151.2093}}
+	quantizers := []*Quantizer{
+		mustQuantizer(t, domain.ResolutionGeohash, 1, 0),
+		mustQuantizer(t, domain.ResolutionGeohash, 6, 0),
+		mustQuantizer(t, domain.ResolutionGeohash, maxGeohashPrecision, 0),
+		mustQuantizer(t, domain.ResolutionGrid, 0, 0.25),
+		mustQuantizer(t, domain.ResolutionGrid, 0, 7),
+	}
+
+	for _, q := range quantizers {
+		for _, p := range points {
+			cell := q.Cell(p[0], p[1])
+			lat, lon, ok := CellCenter(cell.Key)
+			if !ok || math.Abs(lat-cell.Lat) > 1e-9 || math.Abs(lon-cell.Lon) > 1e-9 {
+				t.Errorf("CellCenter(%q) = %v, %v, %v, want %v, %v", cell.Key, lat, lon, ok, cell.Lat, cell.Lon)
+			}
+		}
+	}
+}
+
+func TestCellCenterRejectsMalformedKeys(t *testing.T) {
+	for _, key := range []string{"", "u4pru", "gh5:", "gh5:u4pra", "grid:1:2", "grid0:1:2", "grid1:1", "grid1:a:2", "xx:1"} {
+		if _, _, ok := CellCenter(key); ok {
+			t.Errorf("CellCenter(%q) accepted a malformed key", key)
+		}
+	}
+}
+
+func TestNewQuantizerRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		method      string
+		precision   int
+		gridDegrees float64
+	}{
+		{method: domain.ResolutionGeohash, precision: 0},
+		{method: domain.ResolutionGeohash, precision: maxGeohashPrecision + 1},
+		{method: domain.ResolutionGrid, gridDegrees: 0},
+		{method: domain.ResolutionGrid, gridDegrees: -1},
+		{method: domain.ResolutionGrid, gridDegrees: 10.5},
+		{method: "h3", precision: 6},
+	}
+	for _, tt := range tests {
+		if _, err := NewQuantizer(tt.method, tt.precision, tt.gridDegrees); err == nil {
+			t.Errorf("NewQuantizer(%q, %d, %v) accepted invalid settings", tt.method, tt.precision, tt.gridDegrees)
+		}
+	}
+}
+
+func TestQuantizerWiden(t *testing.T) {
+	geohash := mustQuantizer(t, domain.ResolutionGeohash, 6, 0)
+	if got := geohash.Widen(2).Cell(57.64911, 10.40744).Key; got != "gh4:u4pr" {
+		t.Errorf("Widen(2) geohash key = %q, want gh4:u4pr", got)
+	}
+	if got := geohash.Widen(10).precision; got != 1 {
+		t.Errorf("Widen(10) precision = %d, want 1", got)
+	}
+	if geohash.precision != 6 {
+		t.Errorf("Widen changed the original quantizer")
+	}
+
+	grid := mustQuantizer(t, domain.ResolutionGrid, 0, 0.5)
+	if got := grid.Widen(2).gridDegrees; got != 2 {
+		t.Errorf("Widen(2) grid degrees = %v, want 2", got)
+	}
+	if got := grid.Widen(6).gridDegrees; got != 10 {
+		t.Errorf("Widen(6) grid degrees = %v, want 10", got)
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowAddScript descarta os registros que saíram da janela e grava
+// ARGV[3] novos, todos com o instante ARGV[1] (em milissegundos).
+var slidingWindowAddScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+for i = 1, tonumber(ARGV[3]) do
+	redis.call("ZADD", KEYS[1], now, ARGV[4] .. ":" .. i)
+end
+redis.call("PEXPIRE", KEYS[1], window)
+return redis.call("ZCARD", KEYS[1])
+`)
+
+// SlidingWindowCounter conta eventos em janelas deslizantes no Valkey, de
+// forma compartilhada entre as réplicas. Diferente do UsageCounter, a contagem
+// não zera de uma vez na virada da janela.
+type SlidingWindowCounter struct {
+	client *redis.Client
+}
+
+// NewSlidingWindowCounter cria uma nova instância de SlidingWindowCounter.
+func NewSlidingWindowCounter(client *redis.Client) *SlidingWindowCounter {
+	return &SlidingWindowCounter{
+		client: client,
+	}
+}
+
+// Add registra n eventos agora na chave e retorna quantos há na janela.
+func (c *SlidingWindowCounter) Add(ctx context.Context, key string, n int, window time.Duration) (int64, error) {
+	now := time.Now().UnixMilli()
+	return slidingWindowAddScript.Run(ctx, c.client, []string{"window:" + key},
+		now, window.Milliseconds(), n, uuid.NewString()).Int64()
+}
+
+// Count retorna quantos eventos a chave teve na última janela.
+func (c *SlidingWindowCounter) Count(ctx context.Context, key string, window time.Duration) (int64, error) {
+	now := time.Now().UnixMilli()
+	return c.client.ZCount(ctx, "window:"+key, strconv.FormatInt(now-window.Milliseconds(), 10), "+inf").Result()
+}
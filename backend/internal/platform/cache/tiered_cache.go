@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// invalidationChannel é o canal do Valkey em que as réplicas avisam umas às
+// outras sobre chaves alteradas.
+const invalidationChannel = "weather:invalidate"
+
+// invalidateAll pede que as réplicas esvaziem toda a camada local.
+const invalidateAll = "*"
+
+// maxInvalidationKeys é o máximo de chaves por mensagem de invalidação; acima
+// disso as réplicas esvaziam a camada local inteira.
+const maxInvalidationKeys = 1000
+
+// statsKey guarda no Valkey os contadores de acerto de todas as réplicas.
+const statsKey = "cache:stats"
+
+// statsSampleSize é quantas entradas são lidas para estimar memória e idade.
+const statsSampleSize = 200
+
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// TieredCache combina um LRU em memória, com validade curta, na frente do
+// cache compartilhado no Valkey. Cada escrita ou remoção é publicada para que
+// as outras réplicas descartem sua cópia local.
+type TieredCache struct {
+	local    *LRU[*domain.WeatherData]
+	localTTL time.Duration
+	remote   *WeatherCache
+	client   *redis.Client
+	// instanceID identifica esta réplica nas mensagens de invalidação.
+	instanceID string
+
+	// Contadores ainda não enviados ao Valkey.
+	localHits  atomic.Int64
+	remoteHits atomic.Int64
+	misses     atomic.Int64
+}
+
+// NewTieredCache cria um cache em duas camadas com até localSize entradas em memória.
+func NewTieredCache(remote *WeatherCache, client *redis.Client, localSize int, localTTL time.Duration) *TieredCache {
+	return &TieredCache{
+		local:      NewLRU[*domain.WeatherData](localSize),
+		localTTL:   localTTL,
+		remote:     remote,
+		client:     client,
+		instanceID: uuid.NewString(),
+	}
+}
+
+// Cell retorna a célula da coordenada.
+func (c *TieredCache) Cell(lat, lon float64) Cell {
+	return c.remote.Cell(lat, lon)
+}
+
+// CoarseCell retorna a célula da coordenada steps níveis mais larga.
+func (c *TieredCache) CoarseCell(lat, lon float64, steps int) Cell {
+	return c.remote.CoarseCell(lat, lon, steps)
+}
+
+// Get consulta a camada local e, em caso de miss, o Valkey, guardando o
+// resultado localmente.
+func (c *TieredCache) Get(ctx context.Context, cell Cell) (*domain.WeatherData, error) {
+	key := cacheKey(cell.Key)
+	if data, ok := c.local.Get(key); ok {
+		c.localHits.Add(1)
+		return data, nil
+	}
+
+	data, err := c.remote.Get(ctx, cell)
+	if err != nil || data == nil {
+		if err == nil {
+			c.misses.Add(1)
+		}
+		return data, err
+	}
+	c.remoteHits.Add(1)
+	c.local.Set(key, data, c.localTTL)
+	return data, nil
+}
+
+// Set grava nas duas camadas e invalida as cópias locais das outras réplicas.
+func (c *TieredCache) Set(ctx context.Context, cell Cell, data *domain.WeatherData, expiration time.Duration) error {
+	key := cacheKey(cell.Key)
+	if err := c.remote.Set(ctx, cell, data, expiration); err != nil {
+		return err
+	}
+	c.local.Set(key, data, min(c.localTTL, expiration))
+	c.publish(ctx, key)
+	return nil
+}
+
+// Delete remove a célula das duas camadas, nesta e nas outras réplicas.
+func (c *TieredCache) Delete(ctx context.Context, cell Cell) error {
+	key := cacheKey(cell.Key)
+	c.local.Delete(key)
+	if err := c.remote.Delete(ctx, cell); err != nil {
+		return err
+	}
+	c.publish(ctx, key)
+	return nil
+}
+
+// Inspect retorna a entrada da chave de célula, indicando se ela também está
+// na camada local desta réplica.
+func (c *TieredCache) Inspect(ctx context.Context, cellKey string) (*domain.CacheEntry, error) {
+	entry, err := c.remote.Inspect(ctx, cellKey)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	_, entry.Local = c.local.Get(cacheKey(cellKey))
+	return entry, nil
+}
+
+// Purge remove as entradas escolhidas das duas camadas, nesta e nas outras
+// réplicas, e retorna quantas foram removidas do Valkey.
+func (c *TieredCache) Purge(ctx context.Context, purge *domain.CachePurge) (int, error) {
+	var keys []string
+	switch {
+	case purge.Key != "":
+		keys = []string{cacheKey(purge.Key)}
+	default:
+		err := c.remote.Scan(ctx, purge.Prefix, func(key string) bool {
+			if purge.Region != nil {
+				lat, lon, ok := CellCenter(strings.TrimPrefix(key, KeyNamespace))
+				if !ok || !purge.Region.Contains(lat, lon) {
+					return true
+				}
+			}
+			keys = append(keys, key)
+			return true
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	purged := 0
+	for chunk := range slices.Chunk(keys, scanBatchSize) {
+		n, err := c.client.Del(ctx, chunk...).Result()
+		if err != nil {
+			return purged, err
+		}
+		purged += int(n)
+	}
+
+	if len(keys) > maxInvalidationKeys {
+		c.local.Purge()
+		c.publish(ctx, invalidateAll)
+	} else if len(keys) > 0 {
+		for _, key := range keys {
+			c.local.Delete(key)
+		}
+		c.publish(ctx, keys...)
+	}
+	return purged, nil
+}
+
+// Stats retorna os contadores de acerto de todas as réplicas e o tamanho do cache.
+func (c *TieredCache) Stats(ctx context.Context) (*domain.CacheStats, error) {
+	c.flushStats(ctx)
+
+	stats, err := c.remote.Stats(ctx, statsSampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	counters, err := c.client.HGetAll(ctx, statsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	localHits, _ := strconv.ParseInt(counters["local_hits"], 10, 64)
+	remoteHits, _ := strconv.ParseInt(counters["remote_hits"], 10, 64)
+	stats.Misses, _ = strconv.ParseInt(counters["misses"], 10, 64)
+	stats.LocalHits = localHits
+	stats.Hits = localHits + remoteHits
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats, nil
+}
+
+// RunStatsFlusher envia os contadores de acerto desta réplica ao Valkey a cada
+// intervalo até o contexto ser cancelado.
+func (c *TieredCache) RunStatsFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.flushStats(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			c.flushStats(ctx)
+		}
+	}
+}
+
+func (c *TieredCache) flushStats(ctx context.Context) {
+	counters := map[string]*atomic.Int64{
+		"local_hits":  &c.localHits,
+		"remote_hits": &c.remoteHits,
+		"misses":      &c.misses,
+	}
+
+	pipe := c.client.Pipeline()
+	pending := make(map[string]int64, len(counters))
+	for field, counter := range counters {
+		if n := counter.Swap(0); n > 0 {
+			pending[field] = n
+			pipe.HIncrBy(ctx, statsKey, field, n)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		// Devolve os valores para a próxima tentativa
+		for field, n := range pending {
+			counters[field].Add(n)
+		}
+		log.Printf("failed to flush cache stats: %v", err)
+	}
+}
+
+func (c *TieredCache) publish(ctx context.Context, keys ...string) {
+	msg, err := json.Marshal(invalidation{Origin: c.instanceID, Keys: keys})
+	if err != nil {
+		return
+	}
+	// Uma falha aqui só atrasa a consistência até o fim do TTL local
+	if err := c.client.Publish(ctx, invalidationChannel, msg).Err(); err != nil {
+		log.Printf("failed to publish cache invalidation: %v", err)
+	}
+}
+
+// RunInvalidationListener aplica na camada local as invalidações publicadas
+// pelas outras réplicas até o contexto ser cancelado. Se a inscrição cair, a
+// camada local é esvaziada, pois mensagens podem ter sido perdidas.
+func (c *TieredCache) RunInvalidationListener(ctx context.Context) {
+	for {
+		pubsub := c.client.Subscribe(ctx, invalidationChannel)
+		c.listen(ctx, pubsub)
+		pubsub.Close()
+		c.local.Purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (c *TieredCache) listen(ctx context.Context, pubsub *redis.PubSub) {
+	if _, err := pubsub.Receive(ctx); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("failed to subscribe to cache invalidations: %v", err)
+		}
+		return
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil || inv.Origin == c.instanceID {
+				continue
+			}
+			for _, key := range inv.Keys {
+				if key == invalidateAll {
+					c.local.Purge()
+					break
+				}
+				c.local.Delete(key)
+			}
+		}
+	}
+}
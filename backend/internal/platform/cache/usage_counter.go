@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UsageCounter conta o uso de recursos em janelas fixas no Valkey, de forma
+// compartilhada entre as réplicas.
+type UsageCounter struct {
+	client *redis.Client
+}
+
+// NewUsageCounter cria uma nova instância de UsageCounter.
+func NewUsageCounter(client *redis.Client) *UsageCounter {
+	return &UsageCounter{
+		client: client,
+	}
+}
+
+// Increment soma um ao contador da chave e retorna o novo valor. A chave expira
+// após ttl a partir do primeiro incremento.
+func (c *UsageCounter) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	pipe := c.client.TxPipeline()
+	incr := pipe.Incr(ctx, "usage:"+key)
+	pipe.ExpireNX(ctx, "usage:"+key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
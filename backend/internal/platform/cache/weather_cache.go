@@ -2,30 +2,43 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/weatherpro/backend/internal/core/domain"
 )
 
+// KeyNamespace prefixa todas as chaves de clima. Incremente a versão quando
+// domain.WeatherData mudar de forma incompatível: as entradas antigas deixam
+// de ser lidas e expiram sozinhas.
+const KeyNamespace = "weather:v1:"
+
+// scanBatchSize é quantas chaves cada SCAN pede ao Valkey.
+const scanBatchSize = 500
+
 // WeatherCache é um cache para dados de clima.
 type WeatherCache struct {
-	client *redis.Client
+	client    *redis.Client
+	quantizer *Quantizer
+	codec     *Codec
 }
 
-// NewWeatherCache cria uma nova instância de WeatherCache.
-func NewWeatherCache(client *redis.Client) *WeatherCache {
+// NewWeatherCache cria uma nova instância de WeatherCache. As coordenadas são
+// agrupadas em células pelo quantizer antes de virarem chave, e as previsões
+// são gravadas no formato do codec.
+func NewWeatherCache(client *redis.Client, quantizer *Quantizer, codec *Codec) *WeatherCache {
 	return &WeatherCache{
-		client: client,
+		client:    client,
+		quantizer: quantizer,
+		codec:     codec,
 	}
 }
 
 // Get obtém dados de clima do cache.
-func (c *WeatherCache) Get(ctx context.Context, lat, lon float64) (*domain.WeatherData, error) {
-	key := c.getCacheKey(lat, lon)
-	val, err := c.client.Get(ctx, key).Result()
+func (c *WeatherCache) Get(ctx context.Context, cell Cell) (*domain.WeatherData, error) {
+	val, err := c.client.Get(ctx, cacheKey(cell.Key)).Bytes()
 	if err == redis.Nil {
 		return nil, nil // Cache vazio
 	} else if err != nil {
@@ -33,7 +46,7 @@ func (c *WeatherCache) Get(ctx context.Context, lat, lon float64) (*domain.Weath
 	}
 
 	var weatherData domain.WeatherData
-	if err := json.Unmarshal([]byte(val), &weatherData); err != nil {
+	if err := c.codec.Decode(val, &weatherData); err != nil {
 		return nil, err
 	}
 
@@ -41,18 +54,156 @@ func (c *WeatherCache) Get(ctx context.Context, lat, lon float64) (*domain.Weath
 }
 
 // Set armazena dados de clima no cache.
-func (c *WeatherCache) Set(ctx context.Context, lat, lon float64, data *domain.WeatherData, expiration time.Duration) error {
-	key := c.getCacheKey(lat, lon)
-	val, err := json.Marshal(data)
+func (c *WeatherCache) Set(ctx context.Context, cell Cell, data *domain.WeatherData, expiration time.Duration) error {
+	val, err := c.codec.Encode(data)
 	if err != nil {
 		return err
 	}
 
-	return c.client.Set(ctx, key, val, expiration).Err()
+	return c.client.Set(ctx, cacheKey(cell.Key), val, expiration).Err()
+}
+
+// Delete remove dados de clima do cache.
+func (c *WeatherCache) Delete(ctx context.Context, cell Cell) error {
+	return c.client.Del(ctx, cacheKey(cell.Key)).Err()
+}
+
+// Cell retorna a célula da coordenada.
+func (c *WeatherCache) Cell(lat, lon float64) Cell {
+	return c.quantizer.Cell(lat, lon)
+}
+
+// CoarseCell retorna a célula da coordenada steps níveis mais larga que a
+// configurada.
+func (c *WeatherCache) CoarseCell(lat, lon float64, steps int) Cell {
+	return c.quantizer.Widen(steps).Cell(lat, lon)
+}
+
+// cacheKey retorna a chave completa no Valkey da chave de célula.
+func cacheKey(cellKey string) string {
+	return KeyNamespace + cellKey
+}
+
+// Inspect retorna a entrada da chave de célula com validade e tamanho, ou nil
+// quando ela não está no cache.
+func (c *WeatherCache) Inspect(ctx context.Context, cellKey string) (*domain.CacheEntry, error) {
+	key := cacheKey(cellKey)
+
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	size := pipe.MemoryUsage(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	val, err := get.Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var weatherData domain.WeatherData
+	if err := c.codec.Decode(val, &weatherData); err != nil {
+		return nil, err
+	}
+
+	entry := &domain.CacheEntry{
+		Key:        cellKey,
+		TTLSeconds: int64(ttl.Val().Seconds()),
+		SizeBytes:  size.Val(),
+		Data:       &weatherData,
+	}
+	if !weatherData.FetchedAt.IsZero() {
+		entry.AgeSeconds = int64(time.Since(weatherData.FetchedAt).Seconds())
+	}
+	return entry, nil
+}
+
+// DeleteKeys remove as chaves completas informadas.
+func (c *WeatherCache) DeleteKeys(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Scan percorre as chaves completas do namespace que começam com prefix,
+// parando quando fn retorna false.
+func (c *WeatherCache) Scan(ctx context.Context, prefix string, fn func(key string) bool) error {
+	match := KeyNamespace + escapePattern(prefix) + "*"
+	iter := c.client.Scan(ctx, 0, match, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		if !fn(iter.Val()) {
+			return nil
+		}
+	}
+	return iter.Err()
 }
 
-func (c *WeatherCache) getCacheKey(lat, lon float64) string {
-	return "weather:" + formatFloat(lat) + ":" + formatFloat(lon)
+// Stats conta as entradas do namespace e estima memória e idade média a partir
+// de até sample entradas. Os contadores de acerto ficam com quem chama.
+func (c *WeatherCache) Stats(ctx context.Context, sample int) (*domain.CacheStats, error) {
+	stats := &domain.CacheStats{Namespace: KeyNamespace}
+
+	var keys []string
+	err := c.Scan(ctx, "", func(key string) bool {
+		stats.Entries++
+		if len(keys) < sample {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil || len(keys) == 0 {
+		return stats, err
+	}
+
+	pipe := c.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	sizes := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, key)
+		sizes[i] = pipe.MemoryUsage(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var memory int64
+	var age time.Duration
+	for i := range keys {
+		val, err := gets[i].Bytes()
+		if err != nil {
+			// Expirou entre o SCAN e a leitura
+			continue
+		}
+		var weatherData domain.WeatherData
+		if c.codec.Decode(val, &weatherData) != nil {
+			continue
+		}
+		stats.SampledEntries++
+		memory += sizes[i].Val()
+		age += time.Since(weatherData.FetchedAt)
+	}
+
+	if n := stats.SampledEntries; n > 0 {
+		stats.MemoryBytes = memory * stats.Entries / int64(n)
+		stats.AverageAgeSeconds = age.Seconds() / float64(n)
+	}
+	return stats, nil
+}
+
+// escapePattern escapa os curingas do padrão de SCAN.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
 
 func formatFloat(f float64) string {
@@ -0,0 +1,247 @@
+package appstore
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// OID que a Apple inclui nos certificados folha usados para assinar dados da
+// App Store.
+var appleLeafMarkerOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 11, 1}
+
+var (
+	// ErrInvalidSignature indica um JWS malformado, com assinatura inválida ou
+	// cuja cadeia de certificados não leva à raiz da Apple.
+	ErrInvalidSignature = errors.New("invalid app store signature")
+	// ErrBundleMismatch indica uma transação de outro aplicativo.
+	ErrBundleMismatch = errors.New("app store transaction belongs to another bundle")
+	// ErrEnvironmentMismatch indica uma transação de outro ambiente, como uma
+	// compra de Sandbox ou TestFlight recebida pelo servidor de produção.
+	ErrEnvironmentMismatch = errors.New("app store transaction belongs to another environment")
+)
+
+// Ambientes da App Store.
+const (
+	EnvironmentProduction = "Production"
+	EnvironmentSandbox    = "Sandbox"
+)
+
+// TransactionPayload é o JWSTransactionDecodedPayload da App Store.
+type TransactionPayload struct {
+	TransactionID         string `json:"transactionId"`
+	OriginalTransactionID string `json:"originalTransactionId"`
+	BundleID              string `json:"bundleId"`
+	ProductID             string `json:"productId"`
+	PurchaseDate          int64  `json:"purchaseDate"`
+	ExpiresDate           int64  `json:"expiresDate"`
+	RevocationDate        int64  `json:"revocationDate"`
+	Type                  string `json:"type"`
+	Environment           string `json:"environment"`
+	// OfferType 1 indica uma oferta introdutória (ex.: período de teste).
+	OfferType         int    `json:"offerType"`
+	OfferDiscountType string `json:"offerDiscountType"`
+}
+
+// IsFreeTrial indica uma transação de período de teste gratuito.
+func (t *TransactionPayload) IsFreeTrial() bool {
+	return t.OfferType == 1 && t.OfferDiscountType == "FREE_TRIAL"
+}
+
+// ExpiresAt retorna a data de expiração da transação.
+func (t *TransactionPayload) ExpiresAt() time.Time {
+	return time.UnixMilli(t.ExpiresDate)
+}
+
+// Verifier valida dados assinados pela App Store (JWS com cadeia x5c) contra a
+// raiz de certificados da Apple.
+type Verifier struct {
+	roots       *x509.CertPool
+	bundleID    string
+	environment string
+}
+
+// NewVerifier cria um verificador a partir do PEM do Apple Root CA - G3. Só
+// são aceitas transações e notificações do bundleID no ambiente informado
+// (EnvironmentProduction ou EnvironmentSandbox). Sem rootCAPath ou bundleID,
+// todas as verificações falham.
+func NewVerifier(rootCAPath, bundleID, environment string) (*Verifier, error) {
+	if environment != EnvironmentProduction && environment != EnvironmentSandbox {
+		return nil, fmt.Errorf("invalid app store environment %q", environment)
+	}
+	v := &Verifier{bundleID: bundleID, environment: environment}
+	if rootCAPath == "" {
+		return v, nil
+	}
+
+	pemBytes, err := os.ReadFile(rootCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apple root certificate: %w", err)
+	}
+	v.roots = x509.NewCertPool()
+	if !v.roots.AppendCertsFromPEM(pemBytes) {
+		return nil, errors.New("failed to parse apple root certificate")
+	}
+	return v, nil
+}
+
+// VerifyTransaction valida uma transação assinada (signedTransactionInfo) e
+// confirma que ela pertence ao aplicativo.
+func (v *Verifier) VerifyTransaction(signed string) (*TransactionPayload, error) {
+	var tx TransactionPayload
+	if err := v.Verify(signed, &tx); err != nil {
+		return nil, err
+	}
+	if err := v.checkApp(tx.BundleID, tx.Environment); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// Verify valida a assinatura ES256 e a cadeia de certificados do JWS e
+// decodifica o payload em dst.
+func (v *Verifier) Verify(signed string, dst any) error {
+	if v.roots == nil {
+		return fmt.Errorf("%w: apple root certificate not configured", ErrInvalidSignature)
+	}
+
+	parts := strings.Split(signed, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed JWS", ErrInvalidSignature)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	var header struct {
+		Alg string   `json:"alg"`
+		X5c []string `json:"x5c"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	if header.Alg != "ES256" || len(header.X5c) < 2 {
+		return fmt.Errorf("%w: unexpected algorithm or certificate chain", ErrInvalidSignature)
+	}
+
+	leaf, err := v.verifyChain(header.X5c)
+	if err != nil {
+		return err
+	}
+
+	pub, ok := leaf.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: leaf key is not ECDSA", ErrInvalidSignature)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: malformed payload", ErrInvalidSignature)
+	}
+	return json.Unmarshal(payload, dst)
+}
+
+// verifyChain valida que o certificado folha chega à raiz da Apple através dos
+// intermediários enviados no cabeçalho x5c.
+func (v *Verifier) verifyChain(x5c []string) (*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, len(x5c))
+	for i, encoded := range x5c {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed certificate", ErrInvalidSignature)
+		}
+		certs[i], err = x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed certificate", ErrInvalidSignature)
+		}
+	}
+
+	leaf := certs[0]
+	hasMarker := false
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(appleLeafMarkerOID) {
+			hasMarker = true
+			break
+		}
+	}
+	if !hasMarker {
+		return nil, fmt.Errorf("%w: leaf is not an app store signing certificate", ErrInvalidSignature)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return leaf, nil
+}
+
+// NotificationPayload é o responseBodyV2DecodedPayload das App Store Server
+// Notifications V2.
+type NotificationPayload struct {
+	NotificationType string `json:"notificationType"`
+	Subtype          string `json:"subtype"`
+	NotificationUUID string `json:"notificationUUID"`
+	SignedDate       int64  `json:"signedDate"`
+	Data             struct {
+		BundleID              string `json:"bundleId"`
+		Environment           string `json:"environment"`
+		SignedTransactionInfo string `json:"signedTransactionInfo"`
+		SignedRenewalInfo     string `json:"signedRenewalInfo"`
+		Status                int    `json:"status"`
+	} `json:"data"`
+}
+
+// VerifyNotification valida o signedPayload de uma notificação do servidor da
+// App Store e confirma que ela pertence ao aplicativo.
+func (v *Verifier) VerifyNotification(signedPayload string) (*NotificationPayload, error) {
+	var n NotificationPayload
+	if err := v.Verify(signedPayload, &n); err != nil {
+		return nil, err
+	}
+	if err := v.checkApp(n.Data.BundleID, n.Data.Environment); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// checkApp confirma que os dados assinados são do aplicativo e do ambiente
+// configurados. Uma assinatura válida só prova que vieram da Apple.
+func (v *Verifier) checkApp(bundleID, environment string) error {
+	if v.bundleID == "" {
+		return fmt.Errorf("%w: bundle ID not configured", ErrBundleMismatch)
+	}
+	if bundleID != v.bundleID {
+		return fmt.Errorf("%w: %q", ErrBundleMismatch, bundleID)
+	}
+	if environment != v.environment {
+		return fmt.Errorf("%w: %q", ErrEnvironmentMismatch, environment)
+	}
+	return nil
+}
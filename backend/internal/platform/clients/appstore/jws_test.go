@@ -0,0 +1,303 @@
+package appstore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBundleID = "com.weatherpro.app"
+
+// testPKI é uma cadeia raiz → intermediário → folha no formato da Apple.
+type testPKI struct {
+	rootPEM          []byte
+	leafKey          *ecdsa.PrivateKey
+	x5c              []string
+	leafNoMarkerX5c  []string
+	leafNoMarkerKey  *ecdsa.PrivateKey
+	otherRootLeafX5c []string
+	otherRootLeafKey *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func caTemplate(serial int64, name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+}
+
+func leafTemplate(serial int64, marker bool) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "Prod ECC Mac App Store and iTunes Store Receipt Signing"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if marker {
+		template.ExtraExtensions = []pkix.Extension{{Id: appleLeafMarkerOID, Value: []byte{0x05, 0x00}}}
+	}
+	return template
+}
+
+func encodeX5c(certs ...*x509.Certificate) []string {
+	x5c := make([]string, len(certs))
+	for i, cert := range certs {
+		x5c[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+	return x5c
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	root, rootKey := newTestCert(t, caTemplate(1, "Test Root"), nil, nil)
+	intermediate, intermediateKey := newTestCert(t, caTemplate(2, "Test Intermediate"), root, rootKey)
+	leaf, leafKey := newTestCert(t, leafTemplate(3, true), intermediate, intermediateKey)
+	leafNoMarker, leafNoMarkerKey := newTestCert(t, leafTemplate(4, false), intermediate, intermediateKey)
+
+	otherRoot, otherRootKey := newTestCert(t, caTemplate(5, "Other Root"), nil, nil)
+	otherIntermediate, otherIntermediateKey := newTestCert(t, caTemplate(6, "Other Intermediate"), otherRoot, otherRootKey)
+	otherLeaf, otherLeafKey := newTestCert(t, leafTemplate(7, true), otherIntermediate, otherIntermediateKey)
+
+	return &testPKI{
+		rootPEM:          pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw}),
+		leafKey:          leafKey,
+		x5c:              encodeX5c(leaf, intermediate),
+		leafNoMarkerX5c:  encodeX5c(leafNoMarker, intermediate),
+		leafNoMarkerKey:  leafNoMarkerKey,
+		otherRootLeafX5c: encodeX5c(otherLeaf, otherIntermediate),
+		otherRootLeafKey: otherLeafKey,
+	}
+}
+
+func signJWS(t *testing.T, key *ecdsa.PrivateKey, x5c []string, payload any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]any{"alg": "ES256", "x5c": x5c})
+	body, _ := json.Marshal(payload)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func newTestVerifier(t *testing.T, pki *testPKI, bundleID, environment string) *Verifier {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "root.pem")
+	if err := os.WriteFile(path, pki.rootPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(path, bundleID, environment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	pki := newTestPKI(t)
+	tx := func(bundleID, environment string) map[string]any {
+		return map[string]any{
+			"transactionId":         "2000000000000001",
+			"originalTransactionId": "2000000000000000",
+			"bundleId":              bundleID,
+			"productId":             "premium_monthly",
+			"environment":           environment,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		bundleID    string
+		environment string
+		signed      string
+		wantErr     error
+	}{
+		{
+			name:        "valid production transaction",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.leafKey, pki.x5c, tx(testBundleID, EnvironmentProduction)),
+		},
+		{
+			name:        "valid sandbox transaction on sandbox server",
+			bundleID:    testBundleID,
+			environment: EnvironmentSandbox,
+			signed:      signJWS(t, pki.leafKey, pki.x5c, tx(testBundleID, EnvironmentSandbox)),
+		},
+		{
+			name:        "sandbox transaction on production server",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.leafKey, pki.x5c, tx(testBundleID, EnvironmentSandbox)),
+			wantErr:     ErrEnvironmentMismatch,
+		},
+		{
+			name:        "another app",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.leafKey, pki.x5c, tx("com.other.app", EnvironmentProduction)),
+			wantErr:     ErrBundleMismatch,
+		},
+		{
+			name:        "bundle ID not configured",
+			bundleID:    "",
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.leafKey, pki.x5c, tx("", EnvironmentProduction)),
+			wantErr:     ErrBundleMismatch,
+		},
+		{
+			name:        "leaf without apple marker",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.leafNoMarkerKey, pki.leafNoMarkerX5c, tx(testBundleID, EnvironmentProduction)),
+			wantErr:     ErrInvalidSignature,
+		},
+		{
+			name:        "chain to another root",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.otherRootLeafKey, pki.otherRootLeafX5c, tx(testBundleID, EnvironmentProduction)),
+			wantErr:     ErrInvalidSignature,
+		},
+		{
+			name:        "signed by a key other than the leaf",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      signJWS(t, pki.otherRootLeafKey, pki.x5c, tx(testBundleID, EnvironmentProduction)),
+			wantErr:     ErrInvalidSignature,
+		},
+		{
+			name:        "malformed JWS",
+			bundleID:    testBundleID,
+			environment: EnvironmentProduction,
+			signed:      "not-a-jws",
+			wantErr:     ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, pki, tt.bundleID, tt.environment)
+			got, err := v.VerifyTransaction(tt.signed)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyTransaction() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyTransaction() error = %v", err)
+			}
+			if got.TransactionID != "2000000000000001" {
+				t.Errorf("TransactionID = %q", got.TransactionID)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTamperedPayload(t *testing.T) {
+	pki := newTestPKI(t)
+	v := newTestVerifier(t, pki, testBundleID, EnvironmentProduction)
+
+	signed := signJWS(t, pki.leafKey, pki.x5c, map[string]any{
+		"bundleId":    testBundleID,
+		"environment": EnvironmentProduction,
+		"productId":   "premium_monthly",
+	})
+	parts := strings.Split(signed, ".")
+	forged, _ := json.Marshal(map[string]any{
+		"bundleId":    testBundleID,
+		"environment": EnvironmentProduction,
+		"productId":   "pro_yearly",
+	})
+	parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+
+	if _, err := v.VerifyTransaction(strings.Join(parts, ".")); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifyTransaction() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyNotificationChecksApp(t *testing.T) {
+	pki := newTestPKI(t)
+	v := newTestVerifier(t, pki, testBundleID, EnvironmentProduction)
+
+	notification := func(bundleID, environment string) string {
+		return signJWS(t, pki.leafKey, pki.x5c, map[string]any{
+			"notificationType": "DID_RENEW",
+			"data":             map[string]any{"bundleId": bundleID, "environment": environment},
+		})
+	}
+
+	if _, err := v.VerifyNotification(notification(testBundleID, EnvironmentProduction)); err != nil {
+		t.Fatalf("VerifyNotification() error = %v", err)
+	}
+	if _, err := v.VerifyNotification(notification(testBundleID, EnvironmentSandbox)); !errors.Is(err, ErrEnvironmentMismatch) {
+		t.Errorf("sandbox notification error = %v, want ErrEnvironmentMismatch", err)
+	}
+	if _, err := v.VerifyNotification(notification("com.other.app", EnvironmentProduction)); !errors.Is(err, ErrBundleMismatch) {
+		t.Errorf("other app notification error = %v, want ErrBundleMismatch", err)
+	}
+}
+
+func TestNewVerifierRejectsUnknownEnvironment(t *testing.T) {
+	if _, err := NewVerifier("", testBundleID, "Xcode"); err == nil {
+		t.Fatal("NewVerifier() accepted an unknown environment")
+	}
+}
+
+func TestVerifyWithoutRootFails(t *testing.T) {
+	pki := newTestPKI(t)
+	v, err := NewVerifier("", testBundleID, EnvironmentProduction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signJWS(t, pki.leafKey, pki.x5c, map[string]any{"bundleId": testBundleID, "environment": EnvironmentProduction})
+	if _, err := v.VerifyTransaction(signed); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifyTransaction() error = %v, want ErrInvalidSignature", err)
+	}
+}
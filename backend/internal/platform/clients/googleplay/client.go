@@ -0,0 +1,263 @@
+package googleplay
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	androidPublisherScope = "https://www.googleapis.com/auth/androidpublisher"
+
+	subscriptionV2URL  = "%s/androidpublisher/v3/applications/%s/purchases/subscriptionsv2/tokens/%s"
+	acknowledgeURL     = "%s/androidpublisher/v3/applications/%s/purchases/subscriptions/%s/tokens/%s:acknowledge"
+	defaultTokenURL    = "https://oauth2.googleapis.com/token"
+	tokenRefreshMargin = time.Minute
+)
+
+// Estados de assinatura retornados pela Google Play Developer API.
+const (
+	StateActive        = "SUBSCRIPTION_STATE_ACTIVE"
+	StateInGracePeriod = "SUBSCRIPTION_STATE_IN_GRACE_PERIOD"
+	StateOnHold        = "SUBSCRIPTION_STATE_ON_HOLD"
+	StatePaused        = "SUBSCRIPTION_STATE_PAUSED"
+	StateCanceled      = "SUBSCRIPTION_STATE_CANCELED"
+	StateExpired       = "SUBSCRIPTION_STATE_EXPIRED"
+	StatePending       = "SUBSCRIPTION_STATE_PENDING"
+
+	acknowledgementPending = "ACKNOWLEDGEMENT_STATE_PENDING"
+)
+
+// ErrPurchaseNotFound indica que o token de compra não existe para o app.
+var ErrPurchaseNotFound = errors.New("google play purchase not found")
+
+// SubscriptionPurchase é o recurso SubscriptionPurchaseV2 da Google Play.
+type SubscriptionPurchase struct {
+	SubscriptionState    string     `json:"subscriptionState"`
+	LatestOrderID        string     `json:"latestOrderId"`
+	LinkedPurchaseToken  string     `json:"linkedPurchaseToken"`
+	AcknowledgementState string     `json:"acknowledgementState"`
+	LineItems            []LineItem `json:"lineItems"`
+}
+
+// LineItem é um produto de uma compra de assinatura.
+type LineItem struct {
+	ProductID  string    `json:"productId"`
+	ExpiryTime time.Time `json:"expiryTime"`
+}
+
+// Estrutura da chave de conta de serviço do Google Cloud
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Client é um cliente para a Google Play Developer API.
+type Client struct {
+	httpClient  *http.Client
+	apiURL      string
+	packageName string
+
+	// Credenciais da conta de serviço; sem elas as chamadas seguem sem
+	// autenticação, o que só é útil contra um stub local.
+	clientEmail string
+	privateKey  *rsa.PrivateKey
+	tokenURL    string
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewClient cria um novo cliente da Google Play Developer API. serviceAccountPath
+// aponta para o JSON da conta de serviço com acesso ao Play Console.
+func NewClient(httpClient *http.Client, apiURL, packageName, serviceAccountPath string) (*Client, error) {
+	c := &Client{
+		httpClient:  httpClient,
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		packageName: packageName,
+	}
+	if serviceAccountPath == "" {
+		return c, nil
+	}
+
+	raw, err := os.ReadFile(serviceAccountPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read google service account: %w", err)
+	}
+
+	var key serviceAccountKey
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return nil, fmt.Errorf("failed to decode google service account: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("failed to decode google service account private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse google service account private key: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("google service account private key is not RSA")
+	}
+
+	c.clientEmail = key.ClientEmail
+	c.privateKey = rsaKey
+	c.tokenURL = key.TokenURI
+	if c.tokenURL == "" {
+		c.tokenURL = defaultTokenURL
+	}
+	return c, nil
+}
+
+// PackageName retorna o nome do pacote Android validado por este cliente.
+func (c *Client) PackageName() string {
+	return c.packageName
+}
+
+// GetSubscription obtém o estado atual de uma compra de assinatura.
+func (c *Client) GetSubscription(ctx context.Context, purchaseToken string) (*SubscriptionPurchase, error) {
+	endpoint := fmt.Sprintf(subscriptionV2URL, c.apiURL, url.PathEscape(c.packageName), url.PathEscape(purchaseToken))
+	resp, err := c.do(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get google play subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, ErrPurchaseNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get google play subscription: status code %d", resp.StatusCode)
+	}
+
+	var purchase SubscriptionPurchase
+	if err := json.NewDecoder(resp.Body).Decode(&purchase); err != nil {
+		return nil, fmt.Errorf("failed to decode google play subscription: %w", err)
+	}
+	return &purchase, nil
+}
+
+// AcknowledgeIfPending confirma a compra na Google Play quando ela ainda não
+// foi confirmada; compras não confirmadas em 3 dias são reembolsadas.
+func (c *Client) AcknowledgeIfPending(ctx context.Context, purchase *SubscriptionPurchase, purchaseToken string) error {
+	if purchase.AcknowledgementState != acknowledgementPending || len(purchase.LineItems) == 0 {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf(acknowledgeURL, c.apiURL, url.PathEscape(c.packageName), url.PathEscape(purchase.LineItems[0].ProductID), url.PathEscape(purchaseToken))
+	resp, err := c.do(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge google play subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to acknowledge google play subscription: status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.privateKey != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// token obtém um access token OAuth 2.0 pelo fluxo JWT bearer da conta de
+// serviço, reaproveitando-o até perto de expirar.
+func (c *Client) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Add(tokenRefreshMargin).Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := c.signAssertion(now)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get google access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get google access token: status code %d", resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode google access token: %w", err)
+	}
+
+	c.accessToken = tokenResp.AccessToken
+	c.expiresAt = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return c.accessToken, nil
+}
+
+func (c *Client) signAssertion(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   c.clientEmail,
+		"scope": androidPublisherScope,
+		"aud":   c.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
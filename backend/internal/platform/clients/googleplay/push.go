@@ -0,0 +1,280 @@
+package googleplay
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	certsCacheDuration = time.Hour
+	// minCertsRefetchInterval limita as buscas do JWKS provocadas por kids
+	// desconhecidos, que qualquer um pode escolher no cabeçalho do token.
+	minCertsRefetchInterval = time.Minute
+)
+
+// ErrInvalidPushToken indica uma requisição de push do Pub/Sub sem um token
+// OIDC válido do Google.
+var ErrInvalidPushToken = errors.New("invalid pub/sub push token")
+
+// PushRequest é o corpo enviado pelo Pub/Sub em uma assinatura do tipo push.
+type PushRequest struct {
+	Message struct {
+		Data        string `json:"data"`
+		MessageID   string `json:"messageId"`
+		PublishTime string `json:"publishTime"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// DeveloperNotification é a notificação em tempo real (RTDN) da Google Play,
+// decodificada do campo data da mensagem do Pub/Sub.
+type DeveloperNotification struct {
+	Version                    string                      `json:"version"`
+	PackageName                string                      `json:"packageName"`
+	EventTimeMillis            string                      `json:"eventTimeMillis"`
+	SubscriptionNotification   *SubscriptionNotification   `json:"subscriptionNotification"`
+	VoidedPurchaseNotification *VoidedPurchaseNotification `json:"voidedPurchaseNotification"`
+	TestNotification           *struct {
+		Version string `json:"version"`
+	} `json:"testNotification"`
+}
+
+// SubscriptionNotification descreve a mudança em uma assinatura.
+type SubscriptionNotification struct {
+	Version          string `json:"version"`
+	NotificationType int    `json:"notificationType"`
+	PurchaseToken    string `json:"purchaseToken"`
+	SubscriptionID   string `json:"subscriptionId"`
+}
+
+// VoidedPurchaseNotification informa uma compra reembolsada ou estornada.
+type VoidedPurchaseNotification struct {
+	PurchaseToken string `json:"purchaseToken"`
+	OrderID       string `json:"orderId"`
+	ProductType   int    `json:"productType"`
+	RefundType    int    `json:"refundType"`
+}
+
+var notificationTypeNames = map[int]string{
+	1:  "SUBSCRIPTION_RECOVERED",
+	2:  "SUBSCRIPTION_RENEWED",
+	3:  "SUBSCRIPTION_CANCELED",
+	4:  "SUBSCRIPTION_PURCHASED",
+	5:  "SUBSCRIPTION_ON_HOLD",
+	6:  "SUBSCRIPTION_IN_GRACE_PERIOD",
+	7:  "SUBSCRIPTION_RESTARTED",
+	8:  "SUBSCRIPTION_PRICE_CHANGE_CONFIRMED",
+	9:  "SUBSCRIPTION_DEFERRED",
+	10: "SUBSCRIPTION_PAUSED",
+	11: "SUBSCRIPTION_PAUSE_SCHEDULE_CHANGED",
+	12: "SUBSCRIPTION_REVOKED",
+	13: "SUBSCRIPTION_EXPIRED",
+	17: "SUBSCRIPTION_ITEMS_CHANGED",
+	18: "SUBSCRIPTION_CANCELLATION_SCHEDULED",
+	19: "SUBSCRIPTION_PRICE_CHANGE_UPDATED",
+	20: "SUBSCRIPTION_PENDING_PURCHASE_CANCELED",
+}
+
+// TypeName retorna o nome do tipo de notificação documentado pela Google Play.
+func (n *SubscriptionNotification) TypeName() string {
+	if name, ok := notificationTypeNames[n.NotificationType]; ok {
+		return name
+	}
+	return fmt.Sprintf("SUBSCRIPTION_NOTIFICATION_%d", n.NotificationType)
+}
+
+// Decode extrai a notificação da Google Play da mensagem do Pub/Sub.
+func (p *PushRequest) Decode() (*DeveloperNotification, error) {
+	raw, err := base64.StdEncoding.DecodeString(p.Message.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pub/sub message data: %w", err)
+	}
+
+	var n DeveloperNotification
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return nil, fmt.Errorf("failed to decode developer notification: %w", err)
+	}
+	return &n, nil
+}
+
+// PushVerifier valida o token OIDC que o Pub/Sub envia no cabeçalho
+// Authorization das requisições de push autenticadas.
+type PushVerifier struct {
+	httpClient     *http.Client
+	certsURL       string
+	audience       string
+	serviceAccount string
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewPushVerifier cria um verificador para tokens emitidos para audience em
+// nome da conta de serviço serviceAccount. certsURL aponta para o JWKS do Google.
+// Sem audience ou serviceAccount, todos os tokens são recusados: qualquer
+// projeto do Google consegue emitir um token OIDC válido para uma audiência.
+func NewPushVerifier(httpClient *http.Client, certsURL, audience, serviceAccount string) *PushVerifier {
+	return &PushVerifier{
+		httpClient:     httpClient,
+		certsURL:       certsURL,
+		audience:       audience,
+		serviceAccount: serviceAccount,
+	}
+}
+
+// Verify valida assinatura, emissor, audiência, validade e e-mail do token.
+func (v *PushVerifier) Verify(ctx context.Context, token string) error {
+	if v.audience == "" || v.serviceAccount == "" {
+		return fmt.Errorf("%w: pub/sub audience or service account not configured", ErrInvalidPushToken)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrInvalidPushToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "RS256" {
+		return fmt.Errorf("%w: unexpected header", ErrInvalidPushToken)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidPushToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidPushToken)
+	}
+
+	var claims struct {
+		Iss           string `json:"iss"`
+		Aud           string `json:"aud"`
+		Exp           int64  `json:"exp"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: malformed claims", ErrInvalidPushToken)
+	}
+
+	switch {
+	case claims.Iss != "https://accounts.google.com" && claims.Iss != "accounts.google.com":
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidPushToken)
+	case claims.Aud != v.audience:
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidPushToken)
+	case time.Now().Unix() >= claims.Exp:
+		return fmt.Errorf("%w: token expired", ErrInvalidPushToken)
+	case claims.Email != v.serviceAccount || !claims.EmailVerified:
+		return fmt.Errorf("%w: unexpected service account", ErrInvalidPushToken)
+	}
+	return nil
+}
+
+// key obtém a chave pública pelo kid, atualizando o JWKS quando ele expira ou
+// quando o kid é desconhecido (rotação de chaves). O JWKS é buscado no máximo
+// uma vez por minCertsRefetchInterval; nesse meio-tempo, kids desconhecidos
+// são recusados.
+func (v *PushVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	if ok && time.Since(v.fetchedAt) < certsCacheDuration {
+		return key, nil
+	}
+	if time.Since(v.attemptedAt) < minCertsRefetchInterval {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: unknown key id", ErrInvalidPushToken)
+	}
+
+	v.attemptedAt = time.Now()
+	if err := v.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+	key, ok = v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id", ErrInvalidPushToken)
+	}
+	return key, nil
+}
+
+func (v *PushVerifier) fetchKeys(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.certsURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get google certificates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get google certificates: status code %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("failed to decode google certificates: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+func decodeSegment(segment string, dst any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
@@ -0,0 +1,143 @@
+package googleplay
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testAudience       = "https://api.weatherpro.example/webhooks/google-play"
+	testServiceAccount = "pubsub-push@weatherpro.iam.gserviceaccount.com"
+	testKeyID          = "test-key"
+)
+
+// newTestJWKS serve um JWKS com a chave informada e conta as buscas.
+func newTestJWKS(t *testing.T, key *rsa.PrivateKey) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": testKeyID,
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":            "https://accounts.google.com",
+		"aud":            testAudience,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          testServiceAccount,
+		"email_verified": true,
+	}
+}
+
+func withClaim(name string, value any) map[string]any {
+	claims := validClaims()
+	claims[name] = value
+	return claims
+}
+
+func TestPushVerifierVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := newTestJWKS(t, key)
+
+	tests := []struct {
+		name           string
+		serviceAccount string
+		token          string
+		wantErr        bool
+	}{
+		{name: "valid token", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, validClaims())},
+		{name: "service account not configured", serviceAccount: "", token: signToken(t, key, testKeyID, validClaims()), wantErr: true},
+		{name: "another service account", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("email", "attacker@evil.iam.gserviceaccount.com")), wantErr: true},
+		{name: "email not verified", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("email_verified", false)), wantErr: true},
+		{name: "no email", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("email", "")), wantErr: true},
+		{name: "another audience", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("aud", "https://other.example")), wantErr: true},
+		{name: "another issuer", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("iss", "https://evil.example")), wantErr: true},
+		{name: "expired", serviceAccount: testServiceAccount, token: signToken(t, key, testKeyID, withClaim("exp", time.Now().Add(-time.Minute).Unix())), wantErr: true},
+		{name: "signed by another key", serviceAccount: testServiceAccount, token: signToken(t, otherKey, testKeyID, validClaims()), wantErr: true},
+		{name: "malformed", serviceAccount: testServiceAccount, token: "not.a.token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewPushVerifier(server.Client(), server.URL, testAudience, tt.serviceAccount)
+			err := v.Verify(context.Background(), tt.token)
+			if tt.wantErr && !errors.Is(err, ErrInvalidPushToken) {
+				t.Fatalf("Verify() error = %v, want ErrInvalidPushToken", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPushVerifierLimitsRefetchForUnknownKeyIDs(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, fetches := newTestJWKS(t, key)
+	v := NewPushVerifier(server.Client(), server.URL, testAudience, testServiceAccount)
+
+	if err := v.Verify(context.Background(), signToken(t, key, testKeyID, validClaims())); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		token := signToken(t, key, "attacker-kid-"+string(rune('a'+i)), validClaims())
+		if err := v.Verify(context.Background(), token); !errors.Is(err, ErrInvalidPushToken) {
+			t.Fatalf("Verify() with unknown kid error = %v, want ErrInvalidPushToken", err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+
+	// A chave conhecida continua aceita enquanto o refetch está bloqueado
+	if err := v.Verify(context.Background(), signToken(t, key, testKeyID, validClaims())); err != nil {
+		t.Fatalf("Verify() after unknown kids error = %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 const (
 	currentWeatherAPIURL = "https://api.openweathermap.org/data/2.5/weather?lat=%f&lon=%f&appid=%s&units=metric&lang=pt_br"
 	forecastAPIURL       = "https://api.openweathermap.org/data/2.5/forecast?lat=%f&lon=%f&appid=%s&units=metric&lang=pt_br"
+	reverseGeocodeAPIURL = "https://api.openweathermap.org/geo/1.0/reverse?lat=%f&lon=%f&limit=1&appid=%s"
 )
 
 // Estruturas de resposta para a API /weather
@@ -44,6 +45,13 @@ type weatherResponse struct {
 	Name     string `json:"name"`
 }
 
+// Estrutura de resposta para a API /geo/1.0/reverse
+type reverseGeocodeResponse []struct {
+	Name       string            `json:"name"`
+	LocalNames map[string]string `json:"local_names"`
+	Country    string            `json:"country"`
+}
+
 // Estruturas de resposta para a API /forecast
 type forecastResponse struct {
 	List []forecastItem `json:"list"`
@@ -151,6 +159,34 @@ func (c *Client) GetWeatherData(lat, lon float64) (*domain.WeatherData, error) {
 	return weatherData, nil
 }
 
+// ReverseGeocode retorna o lugar mais próximo da coordenada, com o nome em
+// português quando disponível e o código ISO do país.
+func (c *Client) ReverseGeocode(lat, lon float64) (*domain.Place, error) {
+	resp, err := c.httpClient.Get(fmt.Sprintf(reverseGeocodeAPIURL, lat, lon, c.apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reverse geocode: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to reverse geocode: status code %d", resp.StatusCode)
+	}
+
+	var places reverseGeocodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("failed to decode reverse geocode data: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no place found at %f,%f", lat, lon)
+	}
+
+	place := &domain.Place{Name: places[0].Name, CountryCode: places[0].Country}
+	if name := places[0].LocalNames["pt"]; name != "" {
+		place.Name = name
+	}
+	return place, nil
+}
+
 // Converte os primeiros 8 itens (24h) para a previsão por hora.
 func convertToHourly(items []forecastItem) []domain.HourlyForecast {
 	count := 8
@@ -0,0 +1,160 @@
+package rainviewer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"sync"
+)
+
+const (
+	// TileSize é o tamanho, em pixels, dos tiles de radar solicitados.
+	TileSize = 256
+
+	// O esquema de cores 0 ("black and white values") codifica a refletividade
+	// no nível de cinza do pixel como dBZ + 32.
+	tileURLFormat = "%s%s/%d/%d/%d/%d/0/0_0.png"
+
+	maxCachedTiles = 128
+)
+
+// Estrutura de resposta para a lista de frames de radar
+type weatherMapsResponse struct {
+	Host  string `json:"host"`
+	Radar struct {
+		Past []Frame `json:"past"`
+	} `json:"radar"`
+}
+
+// Frame é um quadro de radar disponível na RainViewer.
+type Frame struct {
+	Time int64  `json:"time"`
+	Path string `json:"path"`
+}
+
+// Client é um cliente para a API pública de radar da RainViewer.
+type Client struct {
+	httpClient *http.Client
+	mapsURL    string
+
+	mu    sync.Mutex
+	tiles map[string][]float32
+	order []string
+}
+
+// NewClient cria um novo cliente da RainViewer. mapsURL aponta para o
+// weather-maps.json, que lista os frames de radar mais recentes.
+func NewClient(httpClient *http.Client, mapsURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		mapsURL:    mapsURL,
+		tiles:      make(map[string][]float32),
+	}
+}
+
+// GetRadarFrames obtém o host dos tiles e os frames de radar passados, do mais
+// antigo para o mais recente.
+func (c *Client) GetRadarFrames(ctx context.Context) (string, []Frame, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.mapsURL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get radar frames: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to get radar frames: status code %d", resp.StatusCode)
+	}
+
+	var maps weatherMapsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&maps); err != nil {
+		return "", nil, fmt.Errorf("failed to decode radar frames: %w", err)
+	}
+
+	return maps.Host, maps.Radar.Past, nil
+}
+
+// GetReflectivityTile obtém um tile de radar e o converte em refletividade
+// (dBZ) por pixel, em ordem de linhas. Pixels sem eco recebem -Inf. Os tiles
+// são imutáveis por frame e ficam em um cache em memória.
+func (c *Client) GetReflectivityTile(ctx context.Context, host string, frame Frame, z, x, y int) ([]float32, error) {
+	url := fmt.Sprintf(tileURLFormat, host, frame.Path, TileSize, z, x, y)
+
+	c.mu.Lock()
+	tile, ok := c.tiles[url]
+	c.mu.Unlock()
+	if ok {
+		return tile, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get radar tile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get radar tile: status code %d", resp.StatusCode)
+	}
+
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode radar tile: %w", err)
+	}
+
+	tile = decodeReflectivity(img)
+	c.storeTile(url, tile)
+	return tile, nil
+}
+
+func (c *Client) storeTile(url string, tile []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.tiles[url]; ok {
+		return
+	}
+	if len(c.order) >= maxCachedTiles {
+		delete(c.tiles, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.tiles[url] = tile
+	c.order = append(c.order, url)
+}
+
+func decodeReflectivity(img image.Image) []float32 {
+	tile := make([]float32, TileSize*TileSize)
+	bounds := img.Bounds()
+
+	for py := 0; py < TileSize; py++ {
+		for px := 0; px < TileSize; px++ {
+			i := py*TileSize + px
+			tile[i] = float32(math.Inf(-1))
+
+			if px >= bounds.Dx() || py >= bounds.Dy() {
+				continue
+			}
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+px, bounds.Min.Y+py)).(color.NRGBA)
+			if c.A == 0 {
+				continue
+			}
+			// O bit mais alto marca neve; os 7 bits restantes trazem dBZ + 32
+			tile[i] = float32(int(c.R&0x7f) - 32)
+		}
+	}
+	return tile
+}
@@ -0,0 +1,215 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Status de assinatura retornados pela API da Stripe.
+const (
+	StatusTrialing          = "trialing"
+	StatusActive            = "active"
+	StatusPastDue           = "past_due"
+	StatusUnpaid            = "unpaid"
+	StatusCanceled          = "canceled"
+	StatusIncomplete        = "incomplete"
+	StatusIncompleteExpired = "incomplete_expired"
+	StatusPaused            = "paused"
+)
+
+// ErrNotFound indica que o recurso não existe na conta da Stripe.
+var ErrNotFound = errors.New("stripe resource not found")
+
+// CheckoutSessionParams descreve uma sessão de checkout de assinatura.
+type CheckoutSessionParams struct {
+	PriceID           string
+	ClientReferenceID string
+	CustomerEmail     string
+	SuccessURL        string
+	CancelURL         string
+	// CouponID aplica um cupom de desconto da Stripe.
+	CouponID string
+	// Metadata é copiada para a assinatura criada pelo checkout.
+	Metadata map[string]string
+}
+
+// CheckoutSession é o recurso checkout.session da Stripe.
+type CheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	Mode              string            `json:"mode"`
+	Status            string            `json:"status"`
+	PaymentStatus     string            `json:"payment_status"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// Subscription é o recurso subscription da Stripe.
+type Subscription struct {
+	ID       string            `json:"id"`
+	Status   string            `json:"status"`
+	Customer string            `json:"customer"`
+	Metadata map[string]string `json:"metadata"`
+	// CurrentPeriodEnd fica no item nas versões mais novas da API; veja PeriodEnd.
+	CurrentPeriodEnd int64 `json:"current_period_end"`
+	EndedAt          int64 `json:"ended_at"`
+	// CancelAtPeriodEnd indica que a renovação automática foi desligada.
+	CancelAtPeriodEnd bool `json:"cancel_at_period_end"`
+	Items             struct {
+		Data []SubscriptionItem `json:"data"`
+	} `json:"items"`
+}
+
+// SubscriptionItem é um preço assinado.
+type SubscriptionItem struct {
+	CurrentPeriodEnd int64 `json:"current_period_end"`
+	Price            struct {
+		ID string `json:"id"`
+	} `json:"price"`
+}
+
+// PriceID retorna o preço do primeiro item da assinatura.
+func (s *Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// PeriodEnd retorna o fim do período pago atual.
+func (s *Subscription) PeriodEnd() time.Time {
+	end := s.CurrentPeriodEnd
+	for _, item := range s.Items.Data {
+		if item.CurrentPeriodEnd > end {
+			end = item.CurrentPeriodEnd
+		}
+	}
+	if end == 0 {
+		return time.Time{}
+	}
+	return time.Unix(end, 0)
+}
+
+// Invoice é o recurso invoice da Stripe, reduzido ao necessário para
+// identificar a assinatura.
+type Invoice struct {
+	ID           string `json:"id"`
+	Customer     string `json:"customer"`
+	Subscription string `json:"subscription"`
+	Parent       struct {
+		SubscriptionDetails struct {
+			Subscription string `json:"subscription"`
+		} `json:"subscription_details"`
+	} `json:"parent"`
+}
+
+// SubscriptionID retorna a assinatura da fatura, nos formatos antigo e novo da API.
+func (i *Invoice) SubscriptionID() string {
+	if i.Subscription != "" {
+		return i.Subscription
+	}
+	return i.Parent.SubscriptionDetails.Subscription
+}
+
+// Client é um cliente para a API da Stripe.
+type Client struct {
+	httpClient    *http.Client
+	apiURL        string
+	secretKey     string
+	webhookSecret string
+}
+
+// NewClient cria um novo cliente da Stripe. apiURL pode apontar para o
+// stripe-mock em desenvolvimento.
+func NewClient(httpClient *http.Client, apiURL, secretKey, webhookSecret string) *Client {
+	return &Client{
+		httpClient:    httpClient,
+		apiURL:        strings.TrimSuffix(apiURL, "/"),
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// CreateCheckoutSession cria uma sessão de checkout no modo assinatura.
+func (c *Client) CreateCheckoutSession(ctx context.Context, params *CheckoutSessionParams) (*CheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", params.PriceID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("success_url", params.SuccessURL)
+	form.Set("cancel_url", params.CancelURL)
+	if params.ClientReferenceID != "" {
+		form.Set("client_reference_id", params.ClientReferenceID)
+	}
+	if params.CouponID != "" {
+		form.Set("discounts[0][coupon]", params.CouponID)
+	}
+	if params.CustomerEmail != "" {
+		form.Set("customer_email", params.CustomerEmail)
+	}
+	for key, value := range params.Metadata {
+		form.Set("metadata["+key+"]", value)
+		form.Set("subscription_data[metadata]["+key+"]", value)
+	}
+
+	var session CheckoutSession
+	if err := c.do(ctx, http.MethodPost, "/v1/checkout/sessions", form, &session); err != nil {
+		return nil, fmt.Errorf("failed to create stripe checkout session: %w", err)
+	}
+	return &session, nil
+}
+
+// GetSubscription obtém o estado atual de uma assinatura.
+func (c *Client) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	var sub Subscription
+	if err := c.do(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(id), nil, &sub); err != nil {
+		return nil, fmt.Errorf("failed to get stripe subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, form url.Values, dst any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("status code %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -0,0 +1,95 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de evento tratados pelo backend.
+const (
+	EventCheckoutSessionCompleted    = "checkout.session.completed"
+	EventInvoicePaid                 = "invoice.paid"
+	EventCustomerSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+// signatureTolerance é a diferença máxima aceita entre o timestamp assinado e
+// o relógio local, para impedir a reutilização de eventos antigos.
+const signatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature indica um evento cujo cabeçalho Stripe-Signature não confere.
+var ErrInvalidSignature = errors.New("invalid stripe signature")
+
+// Event é um evento de webhook da Stripe.
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// ConstructEvent valida o cabeçalho Stripe-Signature ("t=<unix>,v1=<hex>,...")
+// contra o segredo do endpoint e decodifica o evento.
+func (c *Client) ConstructEvent(payload []byte, signatureHeader string) (*Event, error) {
+	if c.webhookSecret == "" {
+		return nil, fmt.Errorf("%w: webhook secret not configured", ErrInvalidSignature)
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return nil, fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.webhookSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	valid := false
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe event: %w", err)
+	}
+	return &event, nil
+}
@@ -0,0 +1,86 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+var testPayload = []byte(`{"id":"evt_1","type":"invoice.paid","created":1767225600,"data":{"object":{"id":"in_1"}}}`)
+
+func sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestConstructEvent(t *testing.T) {
+	now := time.Now().Unix()
+	valid := sign(testWebhookSecret, now, testPayload)
+	ts := strconv.FormatInt(now, 10)
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		wantErr error
+	}{
+		{name: "valid", secret: testWebhookSecret, payload: testPayload, header: "t=" + ts + ",v1=" + valid},
+		{name: "valid among rotated secrets", secret: testWebhookSecret, payload: testPayload,
+			header: "t=" + ts + ",v1=" + sign("whsec_old", now, testPayload) + ",v1=" + valid},
+		{name: "with v0 and spaces", secret: testWebhookSecret, payload: testPayload, header: "t=" + ts + ", v0=abc, v1=" + valid},
+		{name: "secret not configured", secret: "", payload: testPayload, header: "t=" + ts + ",v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "another secret", secret: "whsec_other", payload: testPayload, header: "t=" + ts + ",v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "tampered payload", secret: testWebhookSecret, payload: []byte(`{"id":"evt_2"}`), header: "t=" + ts + ",v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "signed timestamp replaced", secret: testWebhookSecret, payload: testPayload,
+			header: "t=" + strconv.FormatInt(now+1, 10) + ",v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "too old", secret: testWebhookSecret, payload: testPayload,
+			header: fmt.Sprintf("t=%d,v1=%s", now-600, sign(testWebhookSecret, now-600, testPayload)), wantErr: ErrInvalidSignature},
+		{name: "too far in the future", secret: testWebhookSecret, payload: testPayload,
+			header: fmt.Sprintf("t=%d,v1=%s", now+600, sign(testWebhookSecret, now+600, testPayload)), wantErr: ErrInvalidSignature},
+		{name: "no timestamp", secret: testWebhookSecret, payload: testPayload, header: "v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "no signature", secret: testWebhookSecret, payload: testPayload, header: "t=" + ts, wantErr: ErrInvalidSignature},
+		{name: "malformed timestamp", secret: testWebhookSecret, payload: testPayload, header: "t=yesterday,v1=" + valid, wantErr: ErrInvalidSignature},
+		{name: "signature not hex", secret: testWebhookSecret, payload: testPayload, header: "t=" + ts + ",v1=zz", wantErr: ErrInvalidSignature},
+		{name: "empty header", secret: testWebhookSecret, payload: testPayload, header: "", wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(nil, "", "", tt.secret)
+			event, err := c.ConstructEvent(tt.payload, tt.header)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ConstructEvent() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConstructEvent() error = %v", err)
+			}
+			if event.ID != "evt_1" || event.Type != EventInvoicePaid || string(event.Data.Object) != `{"id":"in_1"}` {
+				t.Fatalf("ConstructEvent() = %+v", event)
+			}
+		})
+	}
+}
+
+func TestConstructEventRejectsSignedInvalidJSON(t *testing.T) {
+	payload := []byte(`not json`)
+	now := time.Now().Unix()
+	c := NewClient(nil, "", "", testWebhookSecret)
+
+	_, err := c.ConstructEvent(payload, fmt.Sprintf("t=%d,v1=%s", now, sign(testWebhookSecret, now, payload)))
+	if err == nil || errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("ConstructEvent() error = %v, want a decoding error", err)
+	}
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// Locais vinculados a uma cidade favorita herdam nome e coordenadas dela.
+const alertLocationSelect = `
+		SELECT al.id, al.user_id, al.favorite_city_id, al.is_current_location,
+			COALESCE(fc.city_name, al.name, ''), COALESCE(fc.lat, al.lat, 0), COALESCE(fc.lon, al.lon, 0),
+			al.is_enabled, al.rain_alert, al.severe_weather_alert, al.created_at, al.updated_at
+		FROM alert_locations al
+		LEFT JOIN favorite_cities fc ON fc.id = al.favorite_city_id
+`
+
+// AlertLocationRepository é um repositório para locais de alerta.
+type AlertLocationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAlertLocationRepository cria uma nova instância de AlertLocationRepository.
+func NewAlertLocationRepository(db *pgxpool.Pool) *AlertLocationRepository {
+	return &AlertLocationRepository{
+		db: db,
+	}
+}
+
+// CreateAlertLocation cria um novo local de alerta no banco de dados.
+func (r *AlertLocationRepository) CreateAlertLocation(ctx context.Context, loc *domain.AlertLocation) error {
+	loc.ID = uuid.New()
+	loc.CreatedAt = time.Now()
+	loc.UpdatedAt = loc.CreatedAt
+
+	query := `
+		INSERT INTO alert_locations (id, user_id, favorite_city_id, is_current_location, name, lat, lon, is_enabled, rain_alert, severe_weather_alert, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	name, lat, lon := ownCoordinates(loc)
+	_, err := r.db.Exec(ctx, query,
+		loc.ID,
+		loc.UserID,
+		loc.FavoriteCityID,
+		loc.IsCurrentLocation,
+		name,
+		lat,
+		lon,
+		loc.IsEnabled,
+		loc.RainAlert,
+		loc.SevereWeatherAlert,
+		loc.CreatedAt,
+		loc.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrAlertLocationExists
+	}
+	return err
+}
+
+// GetAlertLocationByID obtém um local de alerta do usuário.
+func (r *AlertLocationRepository) GetAlertLocationByID(ctx context.Context, userID, id uuid.UUID) (*domain.AlertLocation, error) {
+	query := alertLocationSelect + `
+		WHERE al.id = $1 AND al.user_id = $2
+	`
+	loc, err := scanAlertLocation(r.db.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAlertLocationNotFound
+	}
+	return loc, err
+}
+
+// GetCurrentLocation obtém o local "localização atual" do usuário, se existir.
+func (r *AlertLocationRepository) GetCurrentLocation(ctx context.Context, userID uuid.UUID) (*domain.AlertLocation, error) {
+	query := alertLocationSelect + `
+		WHERE al.user_id = $1 AND al.is_current_location
+	`
+	loc, err := scanAlertLocation(r.db.QueryRow(ctx, query, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAlertLocationNotFound
+	}
+	return loc, err
+}
+
+// GetAlertLocationsByUserID obtém todos os locais de alerta de um usuário.
+func (r *AlertLocationRepository) GetAlertLocationsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.AlertLocation, error) {
+	query := alertLocationSelect + `
+		WHERE al.user_id = $1
+		ORDER BY al.is_current_location DESC, al.created_at
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertLocations(rows)
+}
+
+// CountAlertLocationsByUserID conta os locais de alerta de um usuário, sem
+// contar a localização atual do aparelho.
+func (r *AlertLocationRepository) CountAlertLocationsByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM alert_locations
+		WHERE user_id = $1 AND NOT is_current_location
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// ListEnabledAlertLocations obtém os locais de alerta ativos de todos os usuários.
+func (r *AlertLocationRepository) ListEnabledAlertLocations(ctx context.Context) ([]*domain.AlertLocation, error) {
+	query := alertLocationSelect + `
+		WHERE al.is_enabled
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlertLocations(rows)
+}
+
+// UpdateAlertLocation atualiza um local de alerta do usuário.
+func (r *AlertLocationRepository) UpdateAlertLocation(ctx context.Context, loc *domain.AlertLocation) error {
+	loc.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE alert_locations
+		SET name = $3, lat = $4, lon = $5, is_enabled = $6, rain_alert = $7, severe_weather_alert = $8, updated_at = $9
+		WHERE id = $1 AND user_id = $2
+	`
+	name, lat, lon := ownCoordinates(loc)
+	tag, err := r.db.Exec(ctx, query,
+		loc.ID,
+		loc.UserID,
+		name,
+		lat,
+		lon,
+		loc.IsEnabled,
+		loc.RainAlert,
+		loc.SevereWeatherAlert,
+		loc.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAlertLocationNotFound
+	}
+	return nil
+}
+
+// DeleteAlertLocation remove um local de alerta do usuário.
+func (r *AlertLocationRepository) DeleteAlertLocation(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		DELETE FROM alert_locations
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAlertLocationNotFound
+	}
+	return nil
+}
+
+// ownCoordinates retorna os valores das colunas name/lat/lon; locais
+// vinculados a uma favorita não guardam cópia deles.
+func ownCoordinates(loc *domain.AlertLocation) (*string, *float64, *float64) {
+	if loc.FavoriteCityID != nil {
+		return nil, nil, nil
+	}
+	return &loc.Name, &loc.Lat, &loc.Lon
+}
+
+func scanAlertLocation(row pgx.Row) (*domain.AlertLocation, error) {
+	loc := &domain.AlertLocation{}
+	err := row.Scan(
+		&loc.ID,
+		&loc.UserID,
+		&loc.FavoriteCityID,
+		&loc.IsCurrentLocation,
+		&loc.Name,
+		&loc.Lat,
+		&loc.Lon,
+		&loc.IsEnabled,
+		&loc.RainAlert,
+		&loc.SevereWeatherAlert,
+		&loc.CreatedAt,
+		&loc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+func scanAlertLocations(rows pgx.Rows) ([]*domain.AlertLocation, error) {
+	locations := []*domain.AlertLocation{}
+	for rows.Next() {
+		loc, err := scanAlertLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
@@ -2,12 +2,20 @@ package database
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/weatherpro/backend/internal/core/domain"
 )
 
+const favoriteCitySelect = `
+		SELECT id, user_id, city_name, COALESCE(nickname, ''), lat, lon, country_code, position, group_id, tags, created_at, updated_at, version
+		FROM favorite_cities
+`
+
 // FavoriteCityRepository é um repositório para cidades favoritas.
 type FavoriteCityRepository struct {
 	db *pgxpool.Pool
@@ -20,25 +28,40 @@ func NewFavoriteCityRepository(db *pgxpool.Pool) *FavoriteCityRepository {
 	}
 }
 
-// CreateFavoriteCity cria uma nova cidade favorita no banco de dados.
+// CreateFavoriteCity cria uma nova cidade favorita no banco de dados, no fim
+// da lista do usuário.
 func (r *FavoriteCityRepository) CreateFavoriteCity(ctx context.Context, city *domain.FavoriteCity) error {
 	city.ID = uuid.New()
 	query := `
-		INSERT INTO favorite_cities (id, user_id, city_name, lat, lon, country_code)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO favorite_cities (id, user_id, city_name, nickname, lat, lon, country_code, group_id, tags, position)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9,
+			(SELECT COALESCE(MAX(position) + 1, 0) FROM favorite_cities WHERE user_id = $2))
+		RETURNING position, created_at, updated_at, version
 	`
-	_, err := r.db.Exec(ctx, query, city.ID, city.UserID, city.CityName, city.Lat, city.Lon, city.CountryCode)
+	err := r.db.QueryRow(ctx, query, city.ID, city.UserID, city.CityName, city.Nickname, city.Lat, city.Lon, city.CountryCode, city.GroupID, city.Tags).
+		Scan(&city.Position, &city.CreatedAt, &city.UpdatedAt, &city.Version)
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteCityExists
+	}
 	return err
 }
 
-// GetFavoriteCitiesByUserID obtém todas as cidades favoritas de um usuário.
+// GetFavoriteCitiesByUserID obtém todas as cidades favoritas de um usuário, na
+// ordem escolhida por ele.
 func (r *FavoriteCityRepository) GetFavoriteCitiesByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.FavoriteCity, error) {
-	query := `
-		SELECT id, user_id, city_name, lat, lon, country_code
-		FROM favorite_cities
+	return r.ListFavoriteCities(ctx, userID, domain.FavoriteCityFilter{})
+}
+
+// ListFavoriteCities obtém as cidades favoritas do usuário que passam pelo
+// filtro, na ordem escolhida por ele.
+func (r *FavoriteCityRepository) ListFavoriteCities(ctx context.Context, userID uuid.UUID, filter domain.FavoriteCityFilter) ([]*domain.FavoriteCity, error) {
+	query := favoriteCitySelect + `
 		WHERE user_id = $1
+			AND ($2::uuid IS NULL OR group_id = $2)
+			AND ($3::text = '' OR tags @> ARRAY[$3::text])
+		ORDER BY position, created_at
 	`
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, userID, filter.GroupID, filter.Tag)
 	if err != nil {
 		return nil, err
 	}
@@ -46,15 +69,155 @@ func (r *FavoriteCityRepository) GetFavoriteCitiesByUserID(ctx context.Context,
 
 	var cities []*domain.FavoriteCity
 	for rows.Next() {
-		city := &domain.FavoriteCity{}
-		err := rows.Scan(&city.ID, &city.UserID, &city.CityName, &city.Lat, &city.Lon, &city.CountryCode)
+		city, err := scanFavoriteCity(rows)
 		if err != nil {
 			return nil, err
 		}
 		cities = append(cities, city)
 	}
 
-	return cities, nil
+	return cities, rows.Err()
+}
+
+// GetFavoriteCityByID obtém uma cidade favorita pelo ID.
+func (r *FavoriteCityRepository) GetFavoriteCityByID(ctx context.Context, id uuid.UUID) (*domain.FavoriteCity, error) {
+	query := favoriteCitySelect + `
+		WHERE id = $1
+	`
+	return scanFavoriteCity(r.db.QueryRow(ctx, query, id))
+}
+
+// CountFavoriteCitiesByUserID conta as cidades favoritas de um usuário.
+func (r *FavoriteCityRepository) CountFavoriteCitiesByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM favorite_cities
+		WHERE user_id = $1
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// UpdateFavoriteCity atualiza nome, apelido, coordenadas, país, grupo e tags de
+// uma cidade favorita do usuário.
+func (r *FavoriteCityRepository) UpdateFavoriteCity(ctx context.Context, city *domain.FavoriteCity) error {
+	query := `
+		UPDATE favorite_cities
+		SET city_name = $3, nickname = NULLIF($4, ''), lat = $5, lon = $6, country_code = $7, group_id = $8, tags = $9
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at, version
+	`
+	err := r.db.QueryRow(ctx, query, city.ID, city.UserID, city.CityName, city.Nickname, city.Lat, city.Lon, city.CountryCode, city.GroupID, city.Tags).
+		Scan(&city.UpdatedAt, &city.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrFavoriteCityNotFound
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteCityExists
+	}
+	return err
+}
+
+// CreateSyncedFavoriteCity grava uma cidade favorita criada em um aparelho,
+// com o ID, a posição e o horário de alteração que vieram dele.
+func (r *FavoriteCityRepository) CreateSyncedFavoriteCity(ctx context.Context, city *domain.FavoriteCity) error {
+	query := `
+		INSERT INTO favorite_cities (id, user_id, city_name, nickname, lat, lon, country_code, group_id, tags, position, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, version
+	`
+	err := r.db.QueryRow(ctx, query, city.ID, city.UserID, city.CityName, city.Nickname, city.Lat, city.Lon, city.CountryCode, city.GroupID, city.Tags, city.Position, city.UpdatedAt).
+		Scan(&city.CreatedAt, &city.Version)
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteCityExists
+	}
+	return err
+}
+
+// UpdateSyncedFavoriteCity grava uma cidade favorita alterada em um aparelho,
+// desde que ela ainda esteja na versão expectedVersion. Caso contrário,
+// retorna domain.ErrSyncVersionMismatch.
+func (r *FavoriteCityRepository) UpdateSyncedFavoriteCity(ctx context.Context, city *domain.FavoriteCity, expectedVersion int64) error {
+	query := `
+		UPDATE favorite_cities
+		SET city_name = $4, nickname = NULLIF($5, ''), lat = $6, lon = $7, country_code = $8, group_id = $9, tags = $10,
+			position = $11, updated_at = $12
+		WHERE id = $1 AND user_id = $2 AND version = $3
+		RETURNING created_at, version
+	`
+	err := r.db.QueryRow(ctx, query, city.ID, city.UserID, expectedVersion, city.CityName, city.Nickname, city.Lat, city.Lon, city.CountryCode, city.GroupID, city.Tags, city.Position, city.UpdatedAt).
+		Scan(&city.CreatedAt, &city.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrSyncVersionMismatch
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteCityExists
+	}
+	return err
+}
+
+// DeleteSyncedFavoriteCity remove uma cidade favorita apagada em um aparelho,
+// desde que ela ainda esteja na versão expectedVersion, e registra no
+// tombstone o horário em que o aparelho a apagou.
+func (r *FavoriteCityRepository) DeleteSyncedFavoriteCity(ctx context.Context, userID, id uuid.UUID, expectedVersion int64, deletedAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM favorite_cities WHERE id = $1 AND user_id = $2 AND version = $3`, id, userID, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSyncVersionMismatch
+	}
+
+	query := `
+		UPDATE sync_tombstones
+		SET deleted_at = $3
+		WHERE entity = $1 AND record_id = $2
+	`
+	if _, err := tx.Exec(ctx, query, domain.SyncEntityFavoriteCity, id, deletedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReorderFavoriteCities grava a ordem das cidades favoritas do usuário. ids
+// deve conter exatamente as cidades dele; as linhas ficam bloqueadas durante a
+// transação para que uma cidade criada ao mesmo tempo não fique de fora.
+func (r *FavoriteCityRepository) ReorderFavoriteCities(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id FROM favorite_cities WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return err
+	}
+	current, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return err
+	}
+	if !sameIDs(current, ids) {
+		return domain.ErrInvalidFavoriteOrder
+	}
+
+	query := `
+		UPDATE favorite_cities fc
+		SET position = ordered.position - 1
+		FROM UNNEST($2::uuid[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE fc.id = ordered.id AND fc.user_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, userID, ids); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // DeleteFavoriteCity remove uma cidade favorita do banco de dados.
@@ -66,3 +229,44 @@ func (r *FavoriteCityRepository) DeleteFavoriteCity(ctx context.Context, id uuid
 	_, err := r.db.Exec(ctx, query, id)
 	return err
 }
+
+func scanFavoriteCity(row pgx.Row) (*domain.FavoriteCity, error) {
+	city := &domain.FavoriteCity{}
+	err := row.Scan(
+		&city.ID,
+		&city.UserID,
+		&city.CityName,
+		&city.Nickname,
+		&city.Lat,
+		&city.Lon,
+		&city.CountryCode,
+		&city.Position,
+		&city.GroupID,
+		&city.Tags,
+		&city.CreatedAt,
+		&city.UpdatedAt,
+		&city.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return city, nil
+}
+
+// sameIDs informa se as duas listas têm os mesmos IDs, sem repetições.
+func sameIDs(current, ids []uuid.UUID) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+	seen := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
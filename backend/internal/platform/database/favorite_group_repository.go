@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+const favoriteGroupSelect = `
+		SELECT g.id, g.user_id, g.name, COUNT(fc.id), g.created_at
+		FROM favorite_groups g
+		LEFT JOIN favorite_cities fc ON fc.group_id = g.id
+`
+
+// FavoriteGroupRepository é um repositório para grupos de cidades favoritas.
+type FavoriteGroupRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewFavoriteGroupRepository cria uma nova instância de FavoriteGroupRepository.
+func NewFavoriteGroupRepository(db *pgxpool.Pool) *FavoriteGroupRepository {
+	return &FavoriteGroupRepository{
+		db: db,
+	}
+}
+
+// CreateFavoriteGroup cria um novo grupo no banco de dados.
+func (r *FavoriteGroupRepository) CreateFavoriteGroup(ctx context.Context, group *domain.FavoriteGroup) error {
+	group.ID = uuid.New()
+	group.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO favorite_groups (id, user_id, name, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(ctx, query, group.ID, group.UserID, group.Name, group.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteGroupExists
+	}
+	return err
+}
+
+// GetFavoriteGroupByID obtém um grupo do usuário.
+func (r *FavoriteGroupRepository) GetFavoriteGroupByID(ctx context.Context, userID, id uuid.UUID) (*domain.FavoriteGroup, error) {
+	query := favoriteGroupSelect + `
+		WHERE g.id = $1 AND g.user_id = $2
+		GROUP BY g.id
+	`
+	group, err := scanFavoriteGroup(r.db.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrFavoriteGroupNotFound
+	}
+	return group, err
+}
+
+// GetFavoriteGroupsByUserID obtém os grupos de um usuário em ordem alfabética.
+func (r *FavoriteGroupRepository) GetFavoriteGroupsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.FavoriteGroup, error) {
+	query := favoriteGroupSelect + `
+		WHERE g.user_id = $1
+		GROUP BY g.id
+		ORDER BY LOWER(g.name)
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*domain.FavoriteGroup{}
+	for rows.Next() {
+		group, err := scanFavoriteGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// RenameFavoriteGroup altera o nome de um grupo do usuário.
+func (r *FavoriteGroupRepository) RenameFavoriteGroup(ctx context.Context, group *domain.FavoriteGroup) error {
+	query := `
+		UPDATE favorite_groups
+		SET name = $3
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, group.ID, group.UserID, group.Name)
+	if isUniqueViolation(err) {
+		return domain.ErrFavoriteGroupExists
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrFavoriteGroupNotFound
+	}
+	return nil
+}
+
+// DeleteFavoriteGroup remove um grupo do usuário. As cidades dele continuam
+// favoritas, fora de qualquer grupo.
+func (r *FavoriteGroupRepository) DeleteFavoriteGroup(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		DELETE FROM favorite_groups
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrFavoriteGroupNotFound
+	}
+	return nil
+}
+
+func scanFavoriteGroup(row pgx.Row) (*domain.FavoriteGroup, error) {
+	group := &domain.FavoriteGroup{}
+	err := row.Scan(
+		&group.ID,
+		&group.UserID,
+		&group.Name,
+		&group.FavoritesCount,
+		&group.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
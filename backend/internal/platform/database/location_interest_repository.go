@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// LocationInterestRepository lê os pontos que os usuários acompanham.
+type LocationInterestRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewLocationInterestRepository cria uma nova instância de LocationInterestRepository.
+func NewLocationInterestRepository(db *pgxpool.Pool) *LocationInterestRepository {
+	return &LocationInterestRepository{
+		db: db,
+	}
+}
+
+// ListLocationInterests lista cada coordenada distinta por usuário entre as
+// cidades favoritas e os locais de alerta ativos.
+func (r *LocationInterestRepository) ListLocationInterests(ctx context.Context) ([]*domain.LocationInterest, error) {
+	query := `
+		SELECT lat, lon, user_id, MAX(active_at)
+		FROM (
+			SELECT lat, lon, user_id, created_at AS active_at
+			FROM favorite_cities
+			UNION ALL
+			SELECT COALESCE(fc.lat, al.lat), COALESCE(fc.lon, al.lon), al.user_id, al.updated_at
+			FROM alert_locations al
+			LEFT JOIN favorite_cities fc ON fc.id = al.favorite_city_id
+			WHERE al.is_enabled AND COALESCE(fc.lat, al.lat) IS NOT NULL AND COALESCE(fc.lon, al.lon) IS NOT NULL
+		) locations
+		GROUP BY lat, lon, user_id
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interests []*domain.LocationInterest
+	for rows.Next() {
+		li := &domain.LocationInterest{}
+		if err := rows.Scan(&li.Lat, &li.Lon, &li.UserID, &li.LastActiveAt); err != nil {
+			return nil, err
+		}
+		interests = append(interests, li)
+	}
+	return interests, rows.Err()
+}
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// NotificationRepository é um repositório para o histórico de notificações.
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationRepository cria uma nova instância de NotificationRepository.
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// CreateNotification registra uma notificação enviada no banco de dados.
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n *domain.Notification) error {
+	n.ID = uuid.New()
+	n.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO notifications (id, user_id, category, title, body, severity, created_at, deliver_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Exec(ctx, query, n.ID, n.UserID, n.Category, n.Title, n.Body, n.Severity, n.CreatedAt, n.DeliverAt, n.DeliveredAt)
+	return err
+}
+
+// ListNotificationsByUserID obtém as notificações de um usuário, das mais recentes
+// para as mais antigas. Quando before não é nil, retorna apenas as notificações
+// anteriores à posição (before, beforeID).
+func (r *NotificationRepository) ListNotificationsByUserID(ctx context.Context, userID uuid.UUID, before *time.Time, beforeID uuid.UUID, limit int) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, user_id, category, title, body, severity, created_at, read_at, deliver_at, delivered_at
+		FROM notifications
+		WHERE user_id = $1
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, userID, before, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+// ClaimDelivery reserva a notificação para envio, desde que ela não tenha sido
+// entregue nem esteja reservada por outro envio há menos de lease. Retorna
+// false quando outro envio já a reservou.
+func (r *NotificationRepository) ClaimDelivery(ctx context.Context, id uuid.UUID, now time.Time, lease time.Duration) (bool, error) {
+	query := `
+		UPDATE notifications
+		SET claimed_at = $2
+		WHERE id = $1
+			AND delivered_at IS NULL
+			AND (claimed_at IS NULL OR claimed_at <= $2::timestamptz - $3::interval)
+	`
+	tag, err := r.db.Exec(ctx, query, id, now, lease)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimPendingDeliveries reserva até limit notificações adiadas cujo horário de
+// envio já chegou e que não estão reservadas por outro envio há menos de lease.
+func (r *NotificationRepository) ClaimPendingDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Notification, error) {
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM notifications
+			WHERE delivered_at IS NULL AND deliver_at IS NOT NULL AND deliver_at <= $1
+				AND (claimed_at IS NULL OR claimed_at <= $1::timestamptz - $2::interval)
+			ORDER BY deliver_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notifications n
+		SET claimed_at = $1
+		FROM due
+		WHERE n.id = due.id
+		RETURNING n.id, n.user_id, n.category, n.title, n.body, n.severity, n.created_at, n.read_at, n.deliver_at, n.delivered_at
+	`
+	rows, err := r.db.Query(ctx, query, now, lease, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+// ReleaseDeliveryClaim desfaz a reserva de uma notificação cujo envio falhou,
+// para que o despachante a tente de novo sem esperar o fim da reserva.
+func (r *NotificationRepository) ReleaseDeliveryClaim(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET claimed_at = NULL
+		WHERE id = $1 AND delivered_at IS NULL
+	`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
+
+// MarkDelivered registra o instante em que a notificação foi enviada ao dispositivo.
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, id uuid.UUID, t time.Time) error {
+	query := `
+		UPDATE notifications
+		SET delivered_at = $2
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id, t)
+	return err
+}
+
+// CountUnreadByUserID conta as notificações não lidas de um usuário.
+func (r *NotificationRepository) CountUnreadByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkAsRead marca uma notificação do usuário como lida.
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllAsRead marca todas as notificações não lidas do usuário como lidas.
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteOlderThan remove as notificações criadas antes do instante informado.
+func (r *NotificationRepository) DeleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	query := `
+		DELETE FROM notifications
+		WHERE created_at < $1
+	`
+	tag, err := r.db.Exec(ctx, query, t)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanNotifications(rows pgx.Rows) ([]*domain.Notification, error) {
+	notifications := []*domain.Notification{}
+	for rows.Next() {
+		n := &domain.Notification{}
+		err := rows.Scan(&n.ID, &n.UserID, &n.Category, &n.Title, &n.Body, &n.Severity, &n.CreatedAt, &n.ReadAt, &n.DeliverAt, &n.DeliveredAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
@@ -2,12 +2,18 @@ package database
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/weatherpro/backend/internal/core/domain"
 )
 
+const notificationSettingsColumns = `user_id, is_enabled, status_bar_notification, rain_alert, severe_weather_alert,
+		timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end, category_overrides, digest_enabled, digest_time, last_digest_sent_on, updated_at, version`
+
 // NotificationSettingsRepository é um repositório para configurações de notificação.
 type NotificationSettingsRepository struct {
 	db *pgxpool.Pool
@@ -23,40 +29,31 @@ func NewNotificationSettingsRepository(db *pgxpool.Pool) *NotificationSettingsRe
 // GetNotificationSettingsByUserID obtém as configurações de notificação de um usuário.
 func (r *NotificationSettingsRepository) GetNotificationSettingsByUserID(ctx context.Context, userID uuid.UUID) (*domain.NotificationSettings, error) {
 	query := `
-		SELECT user_id, is_enabled, status_bar_notification, rain_alert, severe_weather_alert, alert_location_name, alert_lat, alert_lon
+		SELECT ` + notificationSettingsColumns + `
 		FROM notification_settings
 		WHERE user_id = $1
 	`
-	settings := &domain.NotificationSettings{}
-	err := r.db.QueryRow(ctx, query, userID).Scan(
-		&settings.UserID,
-		&settings.IsEnabled,
-		&settings.StatusBarNotification,
-		&settings.RainAlert,
-		&settings.SevereWeatherAlert,
-		&settings.AlertLocationName,
-		&settings.AlertLat,
-		&settings.AlertLon,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return settings, nil
+	return scanNotificationSettings(r.db.QueryRow(ctx, query, userID))
 }
 
 // UpdateNotificationSettings atualiza as configurações de notificação de um usuário.
 func (r *NotificationSettingsRepository) UpdateNotificationSettings(ctx context.Context, settings *domain.NotificationSettings) error {
 	query := `
-		INSERT INTO notification_settings (user_id, is_enabled, status_bar_notification, rain_alert, severe_weather_alert, alert_location_name, alert_lat, alert_lon)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO notification_settings (user_id, is_enabled, status_bar_notification, rain_alert, severe_weather_alert,
+			timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end, category_overrides, digest_enabled, digest_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (user_id) DO UPDATE SET
 			is_enabled = EXCLUDED.is_enabled,
 			status_bar_notification = EXCLUDED.status_bar_notification,
 			rain_alert = EXCLUDED.rain_alert,
 			severe_weather_alert = EXCLUDED.severe_weather_alert,
-			alert_location_name = EXCLUDED.alert_location_name,
-			alert_lat = EXCLUDED.alert_lat,
-			alert_lon = EXCLUDED.alert_lon
+			timezone = EXCLUDED.timezone,
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			category_overrides = EXCLUDED.category_overrides,
+			digest_enabled = EXCLUDED.digest_enabled,
+			digest_time = EXCLUDED.digest_time
 	`
 	_, err := r.db.Exec(ctx, query,
 		settings.UserID,
@@ -64,9 +61,123 @@ func (r *NotificationSettingsRepository) UpdateNotificationSettings(ctx context.
 		settings.StatusBarNotification,
 		settings.RainAlert,
 		settings.SevereWeatherAlert,
-		settings.AlertLocationName,
-		settings.AlertLat,
-		settings.AlertLon,
+		settings.Timezone,
+		settings.QuietHoursEnabled,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.CategoryOverrides,
+		settings.DigestEnabled,
+		settings.DigestTime,
 	)
 	return err
 }
+
+// SaveSyncedNotificationSettings grava as configurações alteradas em um
+// aparelho, com o horário de alteração que veio dele, desde que elas ainda
+// estejam na versão expectedVersion (exists falso: ainda não existem). Caso
+// contrário, retorna domain.ErrSyncVersionMismatch.
+func (r *NotificationSettingsRepository) SaveSyncedNotificationSettings(ctx context.Context, settings *domain.NotificationSettings, expectedVersion int64, exists bool) error {
+	query := `
+		INSERT INTO notification_settings (user_id, is_enabled, status_bar_notification, rain_alert, severe_weather_alert,
+			timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end, category_overrides, digest_enabled, digest_time, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING version
+	`
+	if exists {
+		query = `
+			UPDATE notification_settings
+			SET is_enabled = $2, status_bar_notification = $3, rain_alert = $4, severe_weather_alert = $5,
+				timezone = $6, quiet_hours_enabled = $7, quiet_hours_start = $8, quiet_hours_end = $9,
+				category_overrides = $10, digest_enabled = $11, digest_time = $12, updated_at = $13
+			WHERE user_id = $1 AND version = $14
+			RETURNING version
+		`
+	}
+
+	args := []any{
+		settings.UserID,
+		settings.IsEnabled,
+		settings.StatusBarNotification,
+		settings.RainAlert,
+		settings.SevereWeatherAlert,
+		settings.Timezone,
+		settings.QuietHoursEnabled,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.CategoryOverrides,
+		settings.DigestEnabled,
+		settings.DigestTime,
+		settings.UpdatedAt,
+	}
+	if exists {
+		args = append(args, expectedVersion)
+	}
+
+	err := r.db.QueryRow(ctx, query, args...).Scan(&settings.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrSyncVersionMismatch
+	}
+	return err
+}
+
+// ListDigestEnabled obtém as configurações de todos os usuários com o resumo diário ativo.
+func (r *NotificationSettingsRepository) ListDigestEnabled(ctx context.Context) ([]*domain.NotificationSettings, error) {
+	query := `
+		SELECT ` + notificationSettingsColumns + `
+		FROM notification_settings
+		WHERE is_enabled AND digest_enabled
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.NotificationSettings
+	for rows.Next() {
+		settings, err := scanNotificationSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, settings)
+	}
+
+	return list, rows.Err()
+}
+
+// MarkDigestSent registra a data local em que o resumo diário foi enviado.
+func (r *NotificationSettingsRepository) MarkDigestSent(ctx context.Context, userID uuid.UUID, day time.Time) error {
+	query := `
+		UPDATE notification_settings
+		SET last_digest_sent_on = $2
+		WHERE user_id = $1
+	`
+	_, err := r.db.Exec(ctx, query, userID, day)
+	return err
+}
+
+func scanNotificationSettings(row pgx.Row) (*domain.NotificationSettings, error) {
+	settings := &domain.NotificationSettings{}
+	err := row.Scan(
+		&settings.UserID,
+		&settings.IsEnabled,
+		&settings.StatusBarNotification,
+		&settings.RainAlert,
+		&settings.SevereWeatherAlert,
+		&settings.Timezone,
+		&settings.QuietHoursEnabled,
+		&settings.QuietHoursStart,
+		&settings.QuietHoursEnd,
+		&settings.CategoryOverrides,
+		&settings.DigestEnabled,
+		&settings.DigestTime,
+		&settings.LastDigestSentOn,
+		&settings.UpdatedAt,
+		&settings.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,3 +22,9 @@ func NewDB(databaseURL string) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// isUniqueViolation informa se o erro é uma violação de restrição UNIQUE.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+const promoCodeSelect = `
+		SELECT id, code, kind, percent_off, duration_days, plan_level, max_redemptions,
+			redemption_count, expires_at, COALESCE(stripe_coupon_id, ''), created_at
+		FROM promo_codes
+`
+
+// PromoCodeRepository é um repositório para códigos promocionais.
+type PromoCodeRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPromoCodeRepository cria uma nova instância de PromoCodeRepository.
+func NewPromoCodeRepository(db *pgxpool.Pool) *PromoCodeRepository {
+	return &PromoCodeRepository{
+		db: db,
+	}
+}
+
+func scanPromoCode(row pgx.Row) (*domain.PromoCode, error) {
+	p := &domain.PromoCode{}
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Kind,
+		&p.PercentOff,
+		&p.DurationDays,
+		&p.PlanLevel,
+		&p.MaxRedemptions,
+		&p.RedemptionCount,
+		&p.ExpiresAt,
+		&p.StripeCouponID,
+		&p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreatePromoCode cadastra um novo código promocional.
+func (r *PromoCodeRepository) CreatePromoCode(ctx context.Context, p *domain.PromoCode) error {
+	p.ID = uuid.New()
+	p.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO promo_codes (id, code, kind, percent_off, duration_days, plan_level, max_redemptions,
+			expires_at, stripe_coupon_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10)
+	`
+	_, err := r.db.Exec(ctx, query, p.ID, p.Code, p.Kind, p.PercentOff, p.DurationDays, p.PlanLevel,
+		p.MaxRedemptions, p.ExpiresAt, p.StripeCouponID, p.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrPromoCodeExists
+	}
+	return err
+}
+
+// ListPromoCodes lista todos os códigos promocionais, dos mais novos aos mais antigos.
+func (r *PromoCodeRepository) ListPromoCodes(ctx context.Context) ([]*domain.PromoCode, error) {
+	rows, err := r.db.Query(ctx, promoCodeSelect+` ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []*domain.PromoCode
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+	return codes, rows.Err()
+}
+
+// GetPromoCodeByCode obtém um código promocional, sem diferenciar maiúsculas.
+func (r *PromoCodeRepository) GetPromoCodeByCode(ctx context.Context, code string) (*domain.PromoCode, error) {
+	p, err := scanPromoCode(r.db.QueryRow(ctx, promoCodeSelect+` WHERE UPPER(code) = UPPER($1)`, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromoCodeNotFound
+	}
+	return p, err
+}
+
+// HasRedeemed informa se o usuário já resgatou o código.
+func (r *PromoCodeRepository) HasRedeemed(ctx context.Context, promoCodeID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM promo_code_redemptions WHERE promo_code_id = $1 AND user_id = $2)`
+	var redeemed bool
+	err := r.db.QueryRow(ctx, query, promoCodeID, userID).Scan(&redeemed)
+	return redeemed, err
+}
+
+// RedeemPromoCode registra o resgate do código pelo usuário. A linha do código
+// fica bloqueada durante a transação para que o limite de resgates não seja
+// ultrapassado por resgates simultâneos.
+func (r *PromoCodeRepository) RedeemPromoCode(ctx context.Context, code string, userID uuid.UUID) (*domain.PromoCode, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanPromoCode(tx.QueryRow(ctx, promoCodeSelect+` WHERE UPPER(code) = UPPER($1) FOR UPDATE`, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := p.Redeemable(now); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO promo_code_redemptions (promo_code_id, user_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, p.ID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, domain.ErrPromoCodeAlreadyRedeemed
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE promo_codes SET redemption_count = redemption_count + 1 WHERE id = $1`, p.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	p.RedemptionCount++
+	return p, nil
+}
+
+// ReleasePromoCodeRedemption desfaz o resgate do usuário, devolvendo o código
+// para que ele possa tentar de novo.
+func (r *PromoCodeRepository) ReleasePromoCodeRedemption(ctx context.Context, promoCodeID, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM promo_code_redemptions WHERE promo_code_id = $1 AND user_id = $2`, promoCodeID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, `UPDATE promo_codes SET redemption_count = redemption_count - 1 WHERE id = $1`, promoCodeID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
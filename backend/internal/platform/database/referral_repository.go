@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReferralRepository é um repositório para indicações entre usuários.
+type ReferralRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewReferralRepository cria uma nova instância de ReferralRepository.
+func NewReferralRepository(db *pgxpool.Pool) *ReferralRepository {
+	return &ReferralRepository{
+		db: db,
+	}
+}
+
+// CreateReferral registra que referredID se cadastrou com o código de
+// referrerID. Cada usuário só pode ter sido indicado uma vez; created indica se
+// a indicação é nova. O indicador ganha rewardDays só enquanto tiver recebido
+// menos de maxRewards recompensas desde since; depois disso, a indicação é
+// registrada sem recompensa para ele e referrerDays volta zero.
+func (r *ReferralRepository) CreateReferral(ctx context.Context, referrerID, referredID uuid.UUID, rewardDays, maxRewards int, since time.Time) (created bool, referrerDays int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serializa as indicações do mesmo indicador para que cadastros
+	// simultâneos não ultrapassem o limite
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, referrerID); err != nil {
+		return false, 0, err
+	}
+
+	var rewarded int
+	query := `
+		SELECT COUNT(*)
+		FROM referrals
+		WHERE referrer_id = $1 AND reward_days > 0 AND created_at >= $2
+	`
+	if err := tx.QueryRow(ctx, query, referrerID, since).Scan(&rewarded); err != nil {
+		return false, 0, err
+	}
+	if rewarded < maxRewards {
+		referrerDays = rewardDays
+	}
+
+	query = `
+		INSERT INTO referrals (referred_id, referrer_id, reward_days, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (referred_id) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, referredID, referrerID, referrerDays, time.Now())
+	if err != nil {
+		return false, 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, 0, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+	return true, referrerDays, nil
+}
+
+// GetReferralStats retorna quantos usuários referrerID indicou e quantos dias
+// ganhou com isso.
+func (r *ReferralRepository) GetReferralStats(ctx context.Context, referrerID uuid.UUID) (count, rewardDays int, err error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(reward_days), 0)
+		FROM referrals
+		WHERE referrer_id = $1
+	`
+	err = r.db.QueryRow(ctx, query, referrerID).Scan(&count, &rewardDays)
+	return count, rewardDays, err
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// SubscriptionEventRepository é um repositório para o histórico de eventos de
+// assinatura. A tabela é somente inserção.
+type SubscriptionEventRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSubscriptionEventRepository cria uma nova instância de SubscriptionEventRepository.
+func NewSubscriptionEventRepository(db *pgxpool.Pool) *SubscriptionEventRepository {
+	return &SubscriptionEventRepository{
+		db: db,
+	}
+}
+
+// CreateSubscriptionEvent registra um evento. Eventos reenviados pela loja, com o
+// mesmo provider_event_id, são ignorados; o retorno indica se o evento é novo.
+func (r *SubscriptionEventRepository) CreateSubscriptionEvent(ctx context.Context, event *domain.SubscriptionEvent) (bool, error) {
+	query := `
+		INSERT INTO subscription_events (id, user_id, provider, provider_subscription_id, provider_event_id,
+			event_type, event_subtype, plan_level, current_period_ends_at, status_from, status_to, payload, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, NULLIF($8, ''), $9, $10, $11, $12, $13)
+		ON CONFLICT (provider, provider_event_id) DO NOTHING
+	`
+	payload := event.Payload
+	if payload == nil {
+		payload = []byte("{}")
+	}
+	tag, err := r.db.Exec(ctx, query,
+		event.ID,
+		event.UserID,
+		event.Provider,
+		event.ProviderSubscriptionID,
+		event.ProviderEventID,
+		event.EventType,
+		event.EventSubtype,
+		event.PlanLevel,
+		event.CurrentPeriodEndsAt,
+		event.StatusFrom,
+		event.StatusTo,
+		payload,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -2,12 +2,17 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/weatherpro/backend/internal/core/domain"
 )
 
+const subscriptionColumns = `user_id, plan_level, current_period_ends_at, provider, provider_subscription_id,
+	status, grace_period_ends_at, trial_used_at, granted_days`
+
 // SubscriptionRepository é um repositório para assinaturas.
 type SubscriptionRepository struct {
 	db *pgxpool.Pool
@@ -20,20 +25,18 @@ func NewSubscriptionRepository(db *pgxpool.Pool) *SubscriptionRepository {
 	}
 }
 
-// GetSubscriptionByUserID obtém a assinatura de um usuário.
-func (r *SubscriptionRepository) GetSubscriptionByUserID(ctx context.Context, userID uuid.UUID) (*domain.Subscription, error) {
-	query := `
-		SELECT user_id, plan_level, current_period_ends_at, provider, provider_subscription_id
-		FROM subscriptions
-		WHERE user_id = $1
-	`
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
 	sub := &domain.Subscription{}
-	err := r.db.QueryRow(ctx, query, userID).Scan(
+	err := row.Scan(
 		&sub.UserID,
 		&sub.PlanLevel,
 		&sub.CurrentPeriodEndsAt,
 		&sub.Provider,
 		&sub.ProviderSubscriptionID,
+		&sub.Status,
+		&sub.GracePeriodEndsAt,
+		&sub.TrialUsedAt,
+		&sub.GrantedDays,
 	)
 	if err != nil {
 		return nil, err
@@ -41,16 +44,35 @@ func (r *SubscriptionRepository) GetSubscriptionByUserID(ctx context.Context, us
 	return sub, nil
 }
 
-// UpdateSubscription atualiza a assinatura de um usuário.
+// GetSubscriptionByUserID obtém a assinatura de um usuário.
+func (r *SubscriptionRepository) GetSubscriptionByUserID(ctx context.Context, userID uuid.UUID) (*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE user_id = $1`
+	return scanSubscription(r.db.QueryRow(ctx, query, userID))
+}
+
+// GetSubscriptionByProviderID obtém a assinatura vinculada a uma assinatura da
+// loja (purchase token, originalTransactionId ou ID da Stripe).
+func (r *SubscriptionRepository) GetSubscriptionByProviderID(ctx context.Context, provider, providerSubscriptionID string) (*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE provider = $1 AND provider_subscription_id = $2`
+	return scanSubscription(r.db.QueryRow(ctx, query, provider, providerSubscriptionID))
+}
+
+// UpdateSubscription atualiza a assinatura de um usuário. A data do primeiro
+// período de teste, uma vez gravada, nunca é sobrescrita.
 func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
 	query := `
-		INSERT INTO subscriptions (user_id, plan_level, current_period_ends_at, provider, provider_subscription_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO subscriptions (user_id, plan_level, current_period_ends_at, provider, provider_subscription_id,
+			status, grace_period_ends_at, trial_used_at, granted_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (user_id) DO UPDATE SET
 			plan_level = EXCLUDED.plan_level,
 			current_period_ends_at = EXCLUDED.current_period_ends_at,
 			provider = EXCLUDED.provider,
-			provider_subscription_id = EXCLUDED.provider_subscription_id
+			provider_subscription_id = EXCLUDED.provider_subscription_id,
+			status = EXCLUDED.status,
+			grace_period_ends_at = EXCLUDED.grace_period_ends_at,
+			trial_used_at = COALESCE(subscriptions.trial_used_at, EXCLUDED.trial_used_at),
+			granted_days = EXCLUDED.granted_days
 	`
 	_, err := r.db.Exec(ctx, query,
 		sub.UserID,
@@ -58,6 +80,87 @@ func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, sub *do
 		sub.CurrentPeriodEndsAt,
 		sub.Provider,
 		sub.ProviderSubscriptionID,
+		sub.Status,
+		sub.GracePeriodEndsAt,
+		sub.TrialUsedAt,
+		sub.GrantedDays,
 	)
+	if isUniqueViolation(err) {
+		return domain.ErrPurchaseInUse
+	}
 	return err
 }
+
+// StartTrial grava um período de teste se o usuário ainda não usou o seu e não
+// tem uma assinatura com direito ao plano. Retorna false quando não pode.
+func (r *SubscriptionRepository) StartTrial(ctx context.Context, sub *domain.Subscription) (bool, error) {
+	query := `
+		INSERT INTO subscriptions (user_id, plan_level, current_period_ends_at, provider, provider_subscription_id,
+			status, grace_period_ends_at, trial_used_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULL, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan_level = EXCLUDED.plan_level,
+			current_period_ends_at = EXCLUDED.current_period_ends_at,
+			provider = EXCLUDED.provider,
+			provider_subscription_id = EXCLUDED.provider_subscription_id,
+			status = EXCLUDED.status,
+			grace_period_ends_at = NULL,
+			trial_used_at = EXCLUDED.trial_used_at
+		WHERE subscriptions.trial_used_at IS NULL AND subscriptions.status = 'expired'
+	`
+	tag, err := r.db.Exec(ctx, query,
+		sub.UserID,
+		sub.PlanLevel,
+		sub.CurrentPeriodEndsAt,
+		sub.Provider,
+		sub.ProviderSubscriptionID,
+		sub.Status,
+		sub.TrialUsedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListLifecycleDue lista as assinaturas cujo prazo do estado atual já passou:
+// períodos encerrados sem renovação e carências esgotadas.
+func (r *SubscriptionRepository) ListLifecycleDue(ctx context.Context, now time.Time) ([]*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE (status IN ('trialing', 'active', 'canceled') AND current_period_ends_at < $1)
+			OR (status IN ('in_grace', 'on_hold') AND COALESCE(grace_period_ends_at, current_period_ends_at) < $1)
+	`
+	rows, err := r.db.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// TransitionStatus muda o estado da assinatura somente se ela ainda estiver no
+// estado from, para não atropelar uma notificação da loja recebida no meio tempo.
+func (r *SubscriptionRepository) TransitionStatus(ctx context.Context, userID uuid.UUID, from, to string, gracePeriodEndsAt *time.Time) (bool, error) {
+	query := `
+		UPDATE subscriptions
+		SET status = $3, grace_period_ends_at = $4,
+			granted_days = CASE WHEN $3 = 'expired' THEN 0 ELSE granted_days END
+		WHERE user_id = $1 AND status = $2
+	`
+	tag, err := r.db.Exec(ctx, query, userID, from, to, gracePeriodEndsAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// SyncRepository lê as alterações a sincronizar entre os aparelhos do usuário.
+type SyncRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSyncRepository cria uma nova instância de SyncRepository.
+func NewSyncRepository(db *pgxpool.Pool) *SyncRepository {
+	return &SyncRepository{
+		db: db,
+	}
+}
+
+// GetSyncChanges obtém os registros do usuário alterados depois da versão
+// since, ou todos eles quando since é nulo. Tudo é lido no mesmo snapshot que
+// o contador de versões, que vira o próximo cursor.
+func (r *SyncRepository) GetSyncChanges(ctx context.Context, userID uuid.UUID, since *int64) (*domain.SyncChanges, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current int64
+	if err := tx.QueryRow(ctx, `SELECT sync_version FROM users WHERE id = $1`, userID).Scan(&current); err != nil {
+		return nil, err
+	}
+	if since != nil && *since > current {
+		return nil, domain.ErrInvalidSyncCursor
+	}
+
+	changes := &domain.SyncChanges{
+		Cursor:    domain.FormatSyncCursor(current),
+		Full:      since == nil,
+		Favorites: []*domain.FavoriteCity{},
+		Deleted:   []*domain.SyncTombstone{},
+	}
+
+	query := favoriteCitySelect + `
+		WHERE user_id = $1 AND ($2::bigint IS NULL OR version > $2)
+		ORDER BY position, created_at
+	`
+	rows, err := tx.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		city, err := scanFavoriteCity(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		changes.Favorites = append(changes.Favorites, city)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// No estado completo não há o que remover
+	if since != nil {
+		query = `
+			SELECT entity, record_id, version, deleted_at
+			FROM sync_tombstones
+			WHERE user_id = $1 AND version > $2
+			ORDER BY version
+		`
+		rows, err := tx.Query(ctx, query, userID, *since)
+		if err != nil {
+			return nil, err
+		}
+		changes.Deleted, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.SyncTombstone, error) {
+			t := &domain.SyncTombstone{}
+			err := row.Scan(&t.Entity, &t.ID, &t.Version, &t.DeletedAt)
+			return t, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `
+		SELECT ` + notificationSettingsColumns + `
+		FROM notification_settings
+		WHERE user_id = $1 AND ($2::bigint IS NULL OR version > $2)
+	`
+	settings, err := scanNotificationSettings(tx.QueryRow(ctx, query, userID, since))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	changes.NotificationSettings = settings
+
+	return changes, nil
+}
+
+// GetTombstone obtém o registro de remoção de um registro do usuário, ou nil
+// se ele não foi apagado.
+func (r *SyncRepository) GetTombstone(ctx context.Context, userID uuid.UUID, entity string, id uuid.UUID) (*domain.SyncTombstone, error) {
+	query := `
+		SELECT entity, record_id, version, deleted_at
+		FROM sync_tombstones
+		WHERE entity = $1 AND record_id = $2 AND user_id = $3
+	`
+	t := &domain.SyncTombstone{}
+	err := r.db.QueryRow(ctx, query, entity, id, userID).Scan(&t.Entity, &t.ID, &t.Version, &t.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -2,9 +2,11 @@ package database
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/weatherpro/backend/internal/core/domain"
 )
@@ -28,27 +30,54 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User) erro
 	user.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO users (id, email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (id, email, password_hash, referral_code, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	_, err := r.db.Exec(ctx, query, user.ID, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.Exec(ctx, query, user.ID, user.Email, user.PasswordHash, user.ReferralCode, user.CreatedAt, user.UpdatedAt)
 	return err
 }
 
 // GetUserByEmail obtém um usuário pelo endereço de e-mail.
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, created_at, updated_at
+		SELECT id, email, password_hash, COALESCE(referral_code, ''), created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.ReferralCode, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
+
+// GetUserByID obtém um usuário pelo ID.
+func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `
+		SELECT id, email, password_hash, COALESCE(referral_code, ''), created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	user := &domain.User{}
+	err := r.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.ReferralCode, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetUserIDByReferralCode obtém o usuário dono de um código de indicação.
+func (r *UserRepository) GetUserIDByReferralCode(ctx context.Context, code string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `SELECT id FROM users WHERE referral_code = $1`, code).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, domain.ErrInvalidReferralCode
+	}
+	return id, err
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// WebhookDeliveryRepository é um repositório para as entregas de webhook
+// pendentes.
+type WebhookDeliveryRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWebhookDeliveryRepository cria uma nova instância de WebhookDeliveryRepository.
+func NewWebhookDeliveryRepository(db *pgxpool.Pool) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		db: db,
+	}
+}
+
+// CreateWebhookDeliveries agenda a entrega do evento aos endpoints para agora.
+// Endpoints que já acumulam maxPending entregas pendentes não recebem a nova,
+// para que um endpoint fora do ar não faça a fila crescer sem limite. Retorna
+// quantas entregas foram agendadas.
+func (r *WebhookDeliveryRepository) CreateWebhookDeliveries(ctx context.Context, event *domain.WebhookEvent, endpointIDs []uuid.UUID, maxPending int) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, event_created_at, payload, next_attempt_at)
+		SELECT gen_random_uuid(), e.id, $2, $3, $4, $5, NOW()
+		FROM UNNEST($1::uuid[]) AS e(id)
+		WHERE (SELECT COUNT(*) FROM webhook_deliveries d WHERE d.endpoint_id = e.id) < $6
+	`
+	tag, err := r.db.Exec(ctx, query, endpointIDs, event.ID, event.Type, event.CreatedAt, []byte(event.Data), maxPending)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDueWebhookDeliveries reserva até limit entregas cujo horário chegou,
+// adiando a próxima tentativa delas em lease. Assim, outra réplica não pega a
+// mesma entrega enquanto esta a envia, e uma réplica que cair no meio deixa a
+// entrega para depois do lease. Entregas de endpoints desativados são
+// descartadas.
+func (r *WebhookDeliveryRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := r.db.Exec(ctx, `
+		DELETE FROM webhook_deliveries d
+		USING webhook_endpoints e
+		WHERE e.id = d.endpoint_id AND NOT e.is_enabled
+	`); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $1::timestamptz + $2::interval
+		FROM due, webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id
+		RETURNING d.id, d.attempts, d.next_attempt_at, d.event_id, d.event_type, d.event_created_at, d.payload,
+			e.id, e.user_id, e.url, e.secret
+	`
+	rows, err := r.db.Query(ctx, query, now, lease, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		d := &domain.WebhookDelivery{Endpoint: &domain.WebhookEndpoint{}, Event: &domain.WebhookEvent{}}
+		err := rows.Scan(
+			&d.ID,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.Event.ID,
+			&d.Event.Type,
+			&d.Event.CreatedAt,
+			&d.Event.Data,
+			&d.Endpoint.ID,
+			&d.Endpoint.UserID,
+			&d.Endpoint.URL,
+			&d.Endpoint.Secret,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RescheduleWebhookDelivery registra uma tentativa falha e agenda a próxima.
+func (r *WebhookDeliveryRepository) RescheduleWebhookDelivery(ctx context.Context, id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = $2, next_attempt_at = $3, last_error = $4
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id, attempts, nextAttemptAt, lastError)
+	return err
+}
+
+// DeleteWebhookDelivery remove uma entrega concluída ou que esgotou as tentativas.
+func (r *WebhookDeliveryRepository) DeleteWebhookDelivery(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM webhook_deliveries
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
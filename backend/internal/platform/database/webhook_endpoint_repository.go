@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+const webhookEndpointSelect = `
+		SELECT id, user_id, url, secret, event_types, is_enabled, consecutive_failures,
+			last_failure_at, COALESCE(last_failure_reason, ''), last_success_at, disabled_at, created_at
+		FROM webhook_endpoints
+`
+
+// WebhookEndpointRepository é um repositório para endpoints de webhook.
+type WebhookEndpointRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWebhookEndpointRepository cria uma nova instância de WebhookEndpointRepository.
+func NewWebhookEndpointRepository(db *pgxpool.Pool) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{
+		db: db,
+	}
+}
+
+// CreateWebhookEndpoint cria um novo endpoint de webhook no banco de dados.
+func (r *WebhookEndpointRepository) CreateWebhookEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	endpoint.ID = uuid.New()
+	endpoint.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO webhook_endpoints (id, user_id, url, secret, event_types, is_enabled, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, endpoint.ID, endpoint.UserID, endpoint.URL, endpoint.Secret, endpoint.EventTypes, endpoint.IsEnabled, endpoint.CreatedAt)
+	return err
+}
+
+// GetWebhookEndpointByID obtém um endpoint de webhook do usuário.
+func (r *WebhookEndpointRepository) GetWebhookEndpointByID(ctx context.Context, userID, id uuid.UUID) (*domain.WebhookEndpoint, error) {
+	query := webhookEndpointSelect + `
+		WHERE id = $1 AND user_id = $2
+	`
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	return endpoint, err
+}
+
+// GetWebhookEndpointsByUserID obtém todos os endpoints de webhook de um usuário.
+func (r *WebhookEndpointRepository) GetWebhookEndpointsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.WebhookEndpoint, error) {
+	query := webhookEndpointSelect + `
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	return r.queryWebhookEndpoints(ctx, query, userID)
+}
+
+// GetEnabledWebhookEndpointsByUserID obtém os endpoints ativos de um usuário.
+func (r *WebhookEndpointRepository) GetEnabledWebhookEndpointsByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.WebhookEndpoint, error) {
+	query := webhookEndpointSelect + `
+		WHERE user_id = $1 AND is_enabled
+	`
+	return r.queryWebhookEndpoints(ctx, query, userID)
+}
+
+// UpdateWebhookEndpoint atualiza a URL, os filtros e o estado de um endpoint.
+// Reativar um endpoint zera o contador de falhas.
+func (r *WebhookEndpointRepository) UpdateWebhookEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $3,
+			event_types = $4,
+			consecutive_failures = CASE WHEN $5 AND NOT is_enabled THEN 0 ELSE consecutive_failures END,
+			disabled_at = CASE WHEN $5 THEN NULL ELSE COALESCE(disabled_at, NOW()) END,
+			is_enabled = $5
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, endpoint.ID, endpoint.UserID, endpoint.URL, endpoint.EventTypes, endpoint.IsEnabled)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+// DeleteWebhookEndpoint remove um endpoint de webhook do usuário.
+func (r *WebhookEndpointRepository) DeleteWebhookEndpoint(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		DELETE FROM webhook_endpoints
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+// RecordSuccess registra uma entrega bem-sucedida e zera o contador de falhas.
+func (r *WebhookEndpointRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET consecutive_failures = 0, last_success_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
+
+// RecordFailure registra uma entrega que esgotou as tentativas. O endpoint é
+// desativado quando atinge maxFailures falhas consecutivas; retorna true nesse caso.
+func (r *WebhookEndpointRepository) RecordFailure(ctx context.Context, id uuid.UUID, reason string, maxFailures int) (bool, error) {
+	query := `
+		UPDATE webhook_endpoints
+		SET consecutive_failures = consecutive_failures + 1,
+			last_failure_at = NOW(),
+			last_failure_reason = $2,
+			is_enabled = is_enabled AND consecutive_failures + 1 < $3,
+			disabled_at = CASE WHEN is_enabled AND consecutive_failures + 1 >= $3 THEN NOW() ELSE disabled_at END
+		WHERE id = $1
+		RETURNING COALESCE(disabled_at = last_failure_at, false)
+	`
+	var disabled bool
+	err := r.db.QueryRow(ctx, query, id, reason, maxFailures).Scan(&disabled)
+	return disabled, err
+}
+
+func (r *WebhookEndpointRepository) queryWebhookEndpoints(ctx context.Context, query string, args ...any) ([]*domain.WebhookEndpoint, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*domain.WebhookEndpoint{}
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+func scanWebhookEndpoint(row pgx.Row) (*domain.WebhookEndpoint, error) {
+	endpoint := &domain.WebhookEndpoint{}
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.UserID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&endpoint.EventTypes,
+		&endpoint.IsEnabled,
+		&endpoint.ConsecutiveFailures,
+		&endpoint.LastFailureAt,
+		&endpoint.LastFailureReason,
+		&endpoint.LastSuccessAt,
+		&endpoint.DisabledAt,
+		&endpoint.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
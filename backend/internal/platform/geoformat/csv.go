@@ -0,0 +1,143 @@
+package geoformat
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+var csvHeader = []string{"name", "nickname", "lat", "lon", "country_code", "tags"}
+
+// csvColumns aceita os nomes de coluna mais comuns em planilhas. O Google My
+// Maps exporta a posição em uma coluna WKT ("POINT (lon lat)").
+var csvColumns = map[string]string{
+	"name":         "name",
+	"city":         "name",
+	"city_name":    "name",
+	"nickname":     "nickname",
+	"lat":          "lat",
+	"latitude":     "lat",
+	"lon":          "lon",
+	"lng":          "lon",
+	"long":         "lon",
+	"longitude":    "lon",
+	"country":      "country_code",
+	"country_code": "country_code",
+	"tags":         "tags",
+	"wkt":          "wkt",
+}
+
+func decodeCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", domain.ErrInvalidFavoriteImport)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Planilhas do Excel costumam gravar o BOM do UTF-8 no início
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if column, ok := csvColumns[name]; ok {
+			columns[column] = i
+		}
+	}
+	_, hasLat := columns["lat"]
+	_, hasLon := columns["lon"]
+	_, hasWKT := columns["wkt"]
+	if !(hasLat && hasLon) && !hasWKT {
+		return nil, fmt.Errorf("%w: CSV must have lat and lon (or WKT) columns", domain.ErrInvalidFavoriteImport)
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("%w: %w", domain.ErrInvalidFavoriteImport, err)
+			}
+			rows = append(rows, Row{Row: parseErr.Line, Err: fmt.Errorf("%w: %v", domain.ErrInvalidFavoriteCity, parseErr.Err)})
+			continue
+		}
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line, _ := reader.FieldPos(0)
+		row := Row{Row: line}
+		city := &domain.FavoriteCity{
+			CityName:    field("name"),
+			Nickname:    field("nickname"),
+			CountryCode: field("country_code"),
+			Tags:        splitTags(field("tags")),
+		}
+		if hasLat && hasLon {
+			city.Lat, city.Lon, row.Err = parseLatLon(field("lat"), field("lon"))
+		} else {
+			city.Lat, city.Lon, row.Err = parseWKTPoint(field("wkt"))
+		}
+		if row.Err == nil {
+			row.City = city
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseLatLon(latStr, lonStr string) (float64, float64, error) {
+	lat, okLat := parseCoordinate(latStr)
+	lon, okLon := parseCoordinate(lonStr)
+	if !okLat || !okLon {
+		return 0, 0, fmt.Errorf("%w: invalid lat/lon %q, %q", domain.ErrInvalidFavoriteCity, latStr, lonStr)
+	}
+	return lat, lon, nil
+}
+
+func parseWKTPoint(wkt string) (float64, float64, error) {
+	inner, ok := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(wkt)), "POINT")
+	inner = strings.TrimSpace(inner)
+	if !ok || !strings.HasPrefix(inner, "(") || !strings.HasSuffix(inner, ")") {
+		return 0, 0, fmt.Errorf("%w: invalid WKT point %q", domain.ErrInvalidFavoriteCity, wkt)
+	}
+	fields := strings.Fields(inner[1 : len(inner)-1])
+	if len(fields) < 2 {
+		return 0, 0, fmt.Errorf("%w: invalid WKT point %q", domain.ErrInvalidFavoriteCity, wkt)
+	}
+	// Em WKT a ordem é longitude, latitude
+	return parseLatLon(fields[1], fields[0])
+}
+
+func encodeCSV(w io.Writer, cities []*domain.FavoriteCity) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, city := range cities {
+		record := []string{
+			city.CityName,
+			city.Nickname,
+			formatCoordinate(city.Lat),
+			formatCoordinate(city.Lon),
+			city.CountryCode,
+			strings.Join(city.Tags, tagSeparator),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,70 @@
+// Package geoformat lê e grava listas de cidades favoritas em GeoJSON, KML e
+// CSV, os formatos aceitos por ferramentas como o Google My Maps e planilhas.
+package geoformat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// tagSeparator separa as tags nos formatos em que elas são um único texto.
+const tagSeparator = ";"
+
+// Row é um local lido do arquivo. Row é a posição dele no arquivo, começando
+// em 1; Err explica por que o local não pôde ser lido.
+type Row struct {
+	Row  int
+	City *domain.FavoriteCity
+	Err  error
+}
+
+// Decode lê os locais do arquivo. Locais malformados voltam com Err, sem
+// impedir a leitura dos demais; um arquivo ilegível retorna erro.
+func Decode(format string, r io.Reader) ([]Row, error) {
+	switch format {
+	case domain.FavoriteFormatGeoJSON:
+		return decodeGeoJSON(r)
+	case domain.FavoriteFormatKML:
+		return decodeKML(r)
+	case domain.FavoriteFormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", domain.ErrUnsupportedFavoriteFormat, format)
+	}
+}
+
+// Encode grava as cidades no formato informado.
+func Encode(format string, w io.Writer, cities []*domain.FavoriteCity) error {
+	switch format {
+	case domain.FavoriteFormatGeoJSON:
+		return encodeGeoJSON(w, cities)
+	case domain.FavoriteFormatKML:
+		return encodeKML(w, cities)
+	case domain.FavoriteFormatCSV:
+		return encodeCSV(w, cities)
+	default:
+		return fmt.Errorf("%w: %q", domain.ErrUnsupportedFavoriteFormat, format)
+	}
+}
+
+// ContentType retorna o tipo MIME do formato.
+func ContentType(format string) string {
+	switch format {
+	case domain.FavoriteFormatGeoJSON:
+		return "application/geo+json"
+	case domain.FavoriteFormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func splitTags(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.Split(s, tagSeparator)
+}
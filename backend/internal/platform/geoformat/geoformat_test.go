@@ -0,0 +1,228 @@
+package geoformat
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// wantRow é o que se espera de um local lido: a cidade ou só o erro.
+type wantRow struct {
+	row      int
+	name     string
+	lat, lon float64
+	err      bool
+}
+
+func checkRows(t *testing.T, got []Row, want []wantRow) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Row != w.row {
+			t.Errorf("row %d: Row = %d, want %d", i, g.Row, w.row)
+		}
+		if w.err {
+			if !errors.Is(g.Err, domain.ErrInvalidFavoriteCity) || g.City != nil {
+				t.Errorf("row %d: Err = %v, City = %+v, want ErrInvalidFavoriteCity", i, g.Err, g.City)
+			}
+			continue
+		}
+		if g.Err != nil {
+			t.Errorf("row %d: Err = %v", i, g.Err)
+			continue
+		}
+		if g.City.CityName != w.name || g.City.Lat != w.lat || g.City.Lon != w.lon {
+			t.Errorf("row %d: City = %q (%v, %v), want %q (%v, %v)", i, g.City.CityName, g.City.Lat, g.City.Lon, w.name, w.lat, w.lon)
+		}
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []wantRow
+		wantErr bool
+	}{
+		{
+			name:  "own format",
+			input: "name,nickname,lat,lon,country_code,tags\nRecife,Casa,-8.05,-34.9,BR,praia;família\n",
+			want:  []wantRow{{row: 2, name: "Recife", lat: -8.05, lon: -34.9}},
+		},
+		{
+			name:  "spreadsheet headers with BOM",
+			input: "\ufeffCity, Latitude, Longitude, Country\n\"Porto Alegre\", -30.03, -51.23, BR\n",
+			want:  []wantRow{{row: 2, name: "Porto Alegre", lat: -30.03, lon: -51.23}},
+		},
+		{
+			name:  "WKT column from My Maps",
+			input: "WKT,name\n\"POINT (-43.2 -22.9)\",Rio\npoint(-46.63 -23.55),São Paulo\n",
+			want:  []wantRow{{row: 2, name: "Rio", lat: -22.9, lon: -43.2}, {row: 3, name: "São Paulo", lat: -23.55, lon: -46.63}},
+		},
+		{
+			name:  "malformed rows do not stop the others",
+			input: "name,lat,lon\nA,abc,1\nB,NaN,1\nC,1,+Inf\nD,-Inf,1\nE,1\"x,2\nF,3,4\n",
+			want: []wantRow{
+				{row: 2, err: true}, {row: 3, err: true}, {row: 4, err: true}, {row: 5, err: true}, {row: 6, err: true},
+				{row: 7, name: "F", lat: 3, lon: 4},
+			},
+		},
+		{
+			name:  "invalid WKT",
+			input: "name,wkt\nA,LINESTRING (1 2, 3 4)\nB,POINT (1)\nC,POINT (nan 1)\n",
+			want:  []wantRow{{row: 2, err: true}, {row: 3, err: true}, {row: 4, err: true}},
+		},
+		{name: "no coordinate columns", input: "name,country\nRecife,BR\n", wantErr: true},
+		{name: "empty file", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Decode(domain.FavoriteFormatCSV, strings.NewReader(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidFavoriteImport) {
+					t.Fatalf("Decode() error = %v, want ErrInvalidFavoriteImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestDecodeKML(t *testing.T) {
+	placemark := func(name, coordinates string) string {
+		return "<Placemark><name>" + name + "</name><Point><coordinates>" + coordinates + "</coordinates></Point></Placemark>"
+	}
+	kml := func(body string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?><kml xmlns="http://www.opengis.net/kml/2.2"><Document>` + body + `</Document></kml>`
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []wantRow
+		wantErr bool
+	}{
+		{
+			name:  "placemarks in folders",
+			input: kml("<Folder><name>Camada</name>" + placemark("Recife", " -34.9,-8.05,0 ") + "</Folder>" + placemark("Rio", "-43.2,-22.9")),
+			want:  []wantRow{{row: 1, name: "Recife", lat: -8.05, lon: -34.9}, {row: 2, name: "Rio", lat: -22.9, lon: -43.2}},
+		},
+		{
+			name: "malformed placemarks do not stop the others",
+			input: kml(placemark("A", "1") + placemark("B", "NaN,1") + placemark("C", "1,Inf") +
+				"<Placemark><name>D</name><LineString><coordinates>1,2 3,4</coordinates></LineString></Placemark>" +
+				placemark("E", "3,4")),
+			want: []wantRow{{row: 1, err: true}, {row: 2, err: true}, {row: 3, err: true}, {row: 4, err: true}, {row: 5, name: "E", lat: 4, lon: 3}},
+		},
+		{name: "no placemark", input: kml(""), wantErr: true},
+		{name: "not XML", input: "<kml><Document>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Decode(domain.FavoriteFormatKML, strings.NewReader(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidFavoriteImport) {
+					t.Fatalf("Decode() error = %v, want ErrInvalidFavoriteImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestDecodeGeoJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []wantRow
+		wantErr bool
+	}{
+		{
+			name: "points and other geometries",
+			input: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[-34.9,-8.05]},"properties":{"name":"Recife"}},
+				{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]},"properties":{"name":"Rota"}},
+				{"type":"Feature","geometry":null,"properties":{"name":"Sem posição"}},
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[1]},"properties":{"name":"Incompleto"}}
+			]}`,
+			want: []wantRow{{row: 1, name: "Recife", lat: -8.05, lon: -34.9}, {row: 2, err: true}, {row: 3, err: true}, {row: 4, err: true}},
+		},
+		{name: "single feature", input: `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}`, wantErr: true},
+		{name: "not JSON", input: `{"type":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Decode(domain.FavoriteFormatGeoJSON, strings.NewReader(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidFavoriteImport) {
+					t.Fatalf("Decode() error = %v, want ErrInvalidFavoriteImport", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+// O que o app exporta precisa voltar igual na importação, em qualquer formato.
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cities := []*domain.FavoriteCity{
+		{CityName: "Recife", Nickname: "Casa", Lat: -8.05, Lon: -34.9, CountryCode: "BR", Tags: []string{"praia", "família"}},
+		{CityName: "Lisboa, Portugal", Lat: 38.7223, Lon: -9.1393, CountryCode: "PT"},
+		{CityName: `Ilha "Grande" & <Cia>`, Lat: -23.1, Lon: -44.2, CountryCode: "BR", Tags: []string{"viagem"}},
+	}
+
+	for _, format := range []string{domain.FavoriteFormatGeoJSON, domain.FavoriteFormatKML, domain.FavoriteFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(format, &buf, cities); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			rows, err := Decode(format, &buf)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(rows) != len(cities) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(cities))
+			}
+			for i, row := range rows {
+				if row.Err != nil {
+					t.Fatalf("row %d: Err = %v", i, row.Err)
+				}
+				if !reflect.DeepEqual(row.City, cities[i]) {
+					t.Errorf("row %d: City = %+v, want %+v", i, row.City, cities[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := Decode("gpx", strings.NewReader("")); !errors.Is(err, domain.ErrUnsupportedFavoriteFormat) {
+		t.Errorf("Decode() error = %v, want ErrUnsupportedFavoriteFormat", err)
+	}
+	if err := Encode("gpx", &bytes.Buffer{}, nil); !errors.Is(err, domain.ErrUnsupportedFavoriteFormat) {
+		t.Errorf("Encode() error = %v, want ErrUnsupportedFavoriteFormat", err)
+	}
+}
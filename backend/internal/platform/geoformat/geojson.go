@@ -0,0 +1,86 @@
+package geoformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string            `json:"type"`
+	Geometry   *geometry         `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+// geometry guarda as coordenadas cruas porque o formato delas depende do tipo:
+// só pontos são lidos, e linhas ou polígonos no mesmo arquivo viram erros por
+// local em vez de invalidar o arquivo inteiro.
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type featureProperties struct {
+	Name        string   `json:"name"`
+	Nickname    string   `json:"nickname,omitempty"`
+	CountryCode string   `json:"country_code,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func decodeGeoJSON(r io.Reader) ([]Row, error) {
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidFavoriteImport, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: expected a GeoJSON FeatureCollection", domain.ErrInvalidFavoriteImport)
+	}
+
+	rows := make([]Row, len(fc.Features))
+	for i, f := range fc.Features {
+		rows[i].Row = i + 1
+		// Em GeoJSON a ordem é longitude, latitude
+		var position []float64
+		if f.Geometry == nil || f.Geometry.Type != "Point" || json.Unmarshal(f.Geometry.Coordinates, &position) != nil || len(position) < 2 {
+			rows[i].Err = fmt.Errorf("%w: feature must be a Point", domain.ErrInvalidFavoriteCity)
+			continue
+		}
+		rows[i].City = &domain.FavoriteCity{
+			CityName:    f.Properties.Name,
+			Nickname:    f.Properties.Nickname,
+			Lat:         position[1],
+			Lon:         position[0],
+			CountryCode: f.Properties.CountryCode,
+			Tags:        f.Properties.Tags,
+		}
+	}
+	return rows, nil
+}
+
+func encodeGeoJSON(w io.Writer, cities []*domain.FavoriteCity) error {
+	fc := featureCollection{Type: "FeatureCollection", Features: make([]feature, len(cities))}
+	for i, city := range cities {
+		position, err := json.Marshal([]float64{city.Lon, city.Lat})
+		if err != nil {
+			return err
+		}
+		fc.Features[i] = feature{
+			Type:     "Feature",
+			Geometry: &geometry{Type: "Point", Coordinates: position},
+			Properties: featureProperties{
+				Name:        city.CityName,
+				Nickname:    city.Nickname,
+				CountryCode: city.CountryCode,
+				Tags:        city.Tags,
+			},
+		}
+	}
+	return json.NewEncoder(w).Encode(fc)
+}
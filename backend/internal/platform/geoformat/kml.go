@@ -0,0 +1,143 @@
+package geoformat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name       string         `xml:"name"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+type kmlPlacemark struct {
+	Name  string    `xml:"name"`
+	Point *kmlPoint `xml:"Point"`
+	Data  []kmlData `xml:"ExtendedData>Data"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// decodeKML lê os Placemarks em qualquer nível do documento, já que o Google
+// My Maps os agrupa em pastas (Folder) por camada.
+func decodeKML(r io.Reader) ([]Row, error) {
+	decoder := xml.NewDecoder(r)
+	var rows []Row
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidFavoriteImport, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var pm kmlPlacemark
+		if err := decoder.DecodeElement(&pm, &start); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidFavoriteImport, err)
+		}
+		row := Row{Row: len(rows) + 1}
+		row.City, row.Err = pm.city()
+		rows = append(rows, row)
+	}
+	if rows == nil {
+		return nil, fmt.Errorf("%w: no Placemark found", domain.ErrInvalidFavoriteImport)
+	}
+	return rows, nil
+}
+
+func (pm *kmlPlacemark) city() (*domain.FavoriteCity, error) {
+	if pm.Point == nil {
+		return nil, fmt.Errorf("%w: placemark must be a Point", domain.ErrInvalidFavoriteCity)
+	}
+	// Em KML a ordem é longitude,latitude[,altitude]
+	parts := strings.Split(strings.TrimSpace(pm.Point.Coordinates), ",")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%w: invalid coordinates %q", domain.ErrInvalidFavoriteCity, pm.Point.Coordinates)
+	}
+	lon, okLon := parseCoordinate(strings.TrimSpace(parts[0]))
+	lat, okLat := parseCoordinate(strings.TrimSpace(parts[1]))
+	if !okLon || !okLat {
+		return nil, fmt.Errorf("%w: invalid coordinates %q", domain.ErrInvalidFavoriteCity, pm.Point.Coordinates)
+	}
+
+	city := &domain.FavoriteCity{CityName: strings.TrimSpace(pm.Name), Lat: lat, Lon: lon}
+	for _, d := range pm.Data {
+		switch d.Name {
+		case "nickname":
+			city.Nickname = d.Value
+		case "country_code":
+			city.CountryCode = d.Value
+		case "tags":
+			city.Tags = splitTags(d.Value)
+		}
+	}
+	return city, nil
+}
+
+func encodeKML(w io.Writer, cities []*domain.FavoriteCity) error {
+	doc := kmlDocument{Xmlns: kmlNamespace}
+	doc.Document.Name = "WeatherPro"
+	doc.Document.Placemarks = make([]kmlPlacemark, len(cities))
+	for i, city := range cities {
+		pm := kmlPlacemark{
+			Name:  city.CityName,
+			Point: &kmlPoint{Coordinates: formatCoordinate(city.Lon) + "," + formatCoordinate(city.Lat)},
+		}
+		pm.Data = append(pm.Data, kmlData{Name: "country_code", Value: city.CountryCode})
+		if city.Nickname != "" {
+			pm.Data = append(pm.Data, kmlData{Name: "nickname", Value: city.Nickname})
+		}
+		if len(city.Tags) > 0 {
+			pm.Data = append(pm.Data, kmlData{Name: "tags", Value: strings.Join(city.Tags, tagSeparator)})
+		}
+		doc.Document.Placemarks[i] = pm
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseCoordinate lê uma latitude ou longitude. ParseFloat também aceita "NaN"
+// e "Inf", que não são coordenadas.
+func parseCoordinate(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
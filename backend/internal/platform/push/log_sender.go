@@ -0,0 +1,23 @@
+package push
+
+import (
+	"context"
+	"log"
+
+	"github.com/weatherpro/backend/internal/core/domain"
+)
+
+// LogSender é um enviador de notificações que apenas registra o envio no log.
+// É usado enquanto não há um provedor de push (FCM/APNs) configurado.
+type LogSender struct{}
+
+// NewLogSender cria um novo LogSender.
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send registra a notificação no log.
+func (s *LogSender) Send(ctx context.Context, n *domain.Notification) error {
+	log.Printf("push notification to user %s [%s/%s]: %s", n.UserID, n.Category, n.Severity, n.Title)
+	return nil
+}
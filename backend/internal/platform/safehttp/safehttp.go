@@ -0,0 +1,86 @@
+// Package safehttp cria clientes HTTP para URLs informadas pelos usuários, que
+// só se conectam a endereços públicos da internet.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress indica uma conexão a um endereço interno, de loopback,
+// link-local (como o serviço de metadados da nuvem) ou de rede privada.
+var ErrForbiddenAddress = errors.New("destination address is not allowed")
+
+// NewClient cria um cliente que recusa endereços não públicos e não segue
+// redirecionamentos. A verificação é feita no IP efetivamente conectado, após
+// a resolução de DNS, o que também cobre DNS rebinding.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		// Um proxy do ambiente faria a conexão no lugar do dialer
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// IsPublicAddr informa se o endereço é roteável na internet pública.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonPublicPrefixes são faixas reservadas que não são cobertas pelos métodos
+// de netip.Addr.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "esta rede"
+	netip.MustParsePrefix("100.64.0.0/10"),   // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // atribuições de protocolo da IETF
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentação
+	netip.MustParsePrefix("198.18.0.0/15"),   // testes de desempenho
+	netip.MustParsePrefix("198.51.100.0/24"), // documentação
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentação
+	netip.MustParsePrefix("240.0.0.0/4"),     // reservado e broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, que pode levar a IPv4 internos
+	netip.MustParsePrefix("2001:db8::/32"),   // documentação
+}
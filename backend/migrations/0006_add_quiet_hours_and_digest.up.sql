@@ -0,0 +1,16 @@
+ALTER TABLE notification_settings
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS quiet_hours_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '22:00',
+    ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '07:00',
+    ADD COLUMN IF NOT EXISTS category_overrides JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS digest_time VARCHAR(5) NOT NULL DEFAULT '07:00',
+    ADD COLUMN IF NOT EXISTS last_digest_sent_on DATE;
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS severity VARCHAR(16) NOT NULL DEFAULT 'info',
+    ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_pending_delivery ON notifications (deliver_at) WHERE delivered_at IS NULL AND deliver_at IS NOT NULL;
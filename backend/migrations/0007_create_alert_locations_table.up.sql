@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS alert_locations (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    favorite_city_id UUID,
+    is_current_location BOOLEAN NOT NULL DEFAULT false,
+    name VARCHAR(255),
+    lat DECIMAL,
+    lon DECIMAL,
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    rain_alert BOOLEAN NOT NULL DEFAULT true,
+    severe_weather_alert BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (favorite_city_id) REFERENCES favorite_cities(id) ON DELETE CASCADE,
+    UNIQUE (user_id, favorite_city_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_locations_current ON alert_locations (user_id) WHERE is_current_location;
+
+-- Migra o local de alerta único de notification_settings para a nova tabela.
+-- O repositório antigo gravava os valores zero do Go, então quem nunca escolheu
+-- um local tem nome vazio e (0, 0); essas linhas não viram locais de alerta
+INSERT INTO alert_locations (id, user_id, name, lat, lon, is_enabled, rain_alert, severe_weather_alert, created_at, updated_at)
+SELECT gen_random_uuid(), user_id, TRIM(alert_location_name), alert_lat, alert_lon, true, rain_alert, severe_weather_alert, NOW(), NOW()
+FROM notification_settings
+WHERE alert_lat IS NOT NULL AND alert_lon IS NOT NULL
+  AND NOT (alert_lat = 0 AND alert_lon = 0)
+  AND TRIM(COALESCE(alert_location_name, '')) <> '';
+
+ALTER TABLE notification_settings
+    DROP COLUMN IF EXISTS alert_location_name,
+    DROP COLUMN IF EXISTS alert_lat,
+    DROP COLUMN IF EXISTS alert_lon;
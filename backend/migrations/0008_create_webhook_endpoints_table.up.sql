@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ,
+    last_failure_reason TEXT,
+    last_success_at TIMESTAMPTZ,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user ON webhook_endpoints (user_id);
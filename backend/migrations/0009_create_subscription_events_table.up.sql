@@ -0,0 +1,30 @@
+-- Registro de auditoria, somente inserção, de tudo que altera assinaturas.
+-- user_id não tem chave estrangeira para que o histórico sobreviva à remoção do usuário.
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id UUID PRIMARY KEY,
+    user_id UUID,
+    provider VARCHAR(255) NOT NULL,
+    provider_subscription_id VARCHAR(255),
+    provider_event_id VARCHAR(255),
+    event_type VARCHAR(255) NOT NULL,
+    event_subtype VARCHAR(255) NOT NULL DEFAULT '',
+    plan_level VARCHAR(255),
+    current_period_ends_at TIMESTAMPTZ,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (provider, provider_event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_events_user ON subscription_events (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_subscription_events_provider_subscription ON subscription_events (provider, provider_subscription_id);
+
+CREATE OR REPLACE FUNCTION forbid_subscription_event_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS subscription_events_append_only ON subscription_events;
+CREATE TRIGGER subscription_events_append_only
+    BEFORE UPDATE OR DELETE ON subscription_events
+    FOR EACH ROW EXECUTE FUNCTION forbid_subscription_event_changes();
@@ -0,0 +1,16 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS grace_period_ends_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS trial_used_at TIMESTAMPTZ;
+
+-- Assinaturas antigas: o plano gratuito ou o período encerrado viram expiradas
+UPDATE subscriptions
+SET status = 'expired'
+WHERE plan_level = 'free' OR current_period_ends_at IS NULL OR current_period_ends_at < NOW();
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_lifecycle ON subscriptions (status, current_period_ends_at)
+    WHERE status <> 'expired';
+
+ALTER TABLE subscription_events
+    ADD COLUMN IF NOT EXISTS status_from VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status_to VARCHAR(32) NOT NULL DEFAULT '';
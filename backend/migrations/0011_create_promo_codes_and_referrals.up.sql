@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id UUID PRIMARY KEY,
+    code VARCHAR(64) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    percent_off INT NOT NULL DEFAULT 0,
+    duration_days INT NOT NULL DEFAULT 0,
+    plan_level VARCHAR(255) NOT NULL DEFAULT 'premium',
+    max_redemptions INT,
+    redemption_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    stripe_coupon_id VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_codes_code ON promo_codes (UPPER(code));
+
+CREATE TABLE IF NOT EXISTS promo_code_redemptions (
+    promo_code_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (promo_code_id, user_id),
+    FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code VARCHAR(16);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_referral_code ON users (referral_code);
+
+-- Códigos para os usuários existentes, no mesmo alfabeto usado no cadastro.
+-- Uma colisão com o índice único sorteia outro código em vez de abortar a
+-- migração.
+DO $$
+DECLARE
+    alphabet CONSTANT TEXT := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
+    u RECORD;
+    new_code TEXT;
+BEGIN
+    FOR u IN SELECT id FROM users WHERE referral_code IS NULL LOOP
+        LOOP
+            new_code := '';
+            FOR i IN 1..8 LOOP
+                new_code := new_code || SUBSTR(alphabet, 1 + FLOOR(RANDOM() * LENGTH(alphabet))::INT, 1);
+            END LOOP;
+            BEGIN
+                UPDATE users SET referral_code = new_code WHERE id = u.id;
+                EXIT;
+            EXCEPTION WHEN unique_violation THEN
+                -- Código já em uso: sorteia outro
+            END;
+        END LOOP;
+    END LOOP;
+END $$;
+
+CREATE TABLE IF NOT EXISTS referrals (
+    referred_id UUID PRIMARY KEY,
+    referrer_id UUID NOT NULL,
+    reward_days INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (referred_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (referrer_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals (referrer_id);
+
+-- Dias concedidos por promoções e indicações que se somam ao período cobrado
+-- pela loja, para não se perderem a cada renovação sincronizada.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS granted_days INT NOT NULL DEFAULT 0;
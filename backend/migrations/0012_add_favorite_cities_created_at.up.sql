@@ -0,0 +1,3 @@
+-- Data em que a cidade foi favoritada, usada para priorizar o aquecimento do cache
+ALTER TABLE favorite_cities
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
@@ -0,0 +1,93 @@
+ALTER TABLE favorite_cities
+    ADD COLUMN IF NOT EXISTS nickname VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+-- Duplicatas (mesmas coordenadas arredondadas a 0,01°) ficam só na favorita
+-- mais antiga, que herda o apelido e os locais de alerta delas
+CREATE TEMPORARY TABLE favorite_city_duplicates AS
+SELECT id, keep_id
+FROM (
+    SELECT id, FIRST_VALUE(id) OVER w AS keep_id
+    FROM favorite_cities
+    WINDOW w AS (PARTITION BY user_id, ROUND(lat, 2), ROUND(lon, 2) ORDER BY created_at, id)
+) ranked
+WHERE id <> keep_id;
+
+UPDATE favorite_cities fc
+SET nickname = merged.nickname
+FROM (
+    SELECT DISTINCT ON (d.keep_id) d.keep_id, dup.nickname
+    FROM favorite_city_duplicates d
+    JOIN favorite_cities dup ON dup.id = d.id
+    WHERE dup.nickname IS NOT NULL
+    ORDER BY d.keep_id, dup.created_at, dup.id
+) merged
+WHERE fc.id = merged.keep_id
+  AND fc.nickname IS NULL;
+
+-- Cada favorita tem no máximo um local de alerta. O que fica é o da favorita
+-- mantida ou, se ela não tiver, o mais antigo das duplicatas, e recebe a
+-- união dos alertas ligados em todos eles
+CREATE TEMPORARY TABLE favorite_city_alert_merge AS
+SELECT al.id, members.keep_id,
+       FIRST_VALUE(al.id) OVER w AS survivor_id,
+       BOOL_OR(al.is_enabled) OVER p AS is_enabled,
+       BOOL_OR(al.rain_alert) OVER p AS rain_alert,
+       BOOL_OR(al.severe_weather_alert) OVER p AS severe_weather_alert
+FROM alert_locations al
+JOIN (
+    SELECT id, keep_id FROM favorite_city_duplicates
+    UNION ALL
+    SELECT DISTINCT keep_id, keep_id FROM favorite_city_duplicates
+) members ON members.id = al.favorite_city_id
+WINDOW p AS (PARTITION BY members.keep_id),
+       w AS (PARTITION BY members.keep_id ORDER BY al.favorite_city_id = members.keep_id DESC, al.created_at, al.id
+             ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING);
+
+DELETE FROM alert_locations al
+USING favorite_city_alert_merge m
+WHERE al.id = m.id
+  AND m.id <> m.survivor_id;
+
+UPDATE alert_locations al
+SET favorite_city_id = m.keep_id,
+    is_enabled = m.is_enabled,
+    rain_alert = m.rain_alert,
+    severe_weather_alert = m.severe_weather_alert,
+    updated_at = NOW()
+FROM favorite_city_alert_merge m
+WHERE al.id = m.survivor_id
+  AND m.id = m.survivor_id;
+
+-- Nada mais pode depender das duplicatas: o ON DELETE CASCADE apagaria em
+-- silêncio o que ficou para trás
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM alert_locations al
+        JOIN favorite_city_duplicates d ON d.id = al.favorite_city_id
+    ) THEN
+        RAISE EXCEPTION 'alert_locations still reference duplicate favorite_cities';
+    END IF;
+END
+$$;
+
+DELETE FROM favorite_cities fc
+USING favorite_city_duplicates d
+WHERE fc.id = d.id;
+
+DROP TABLE favorite_city_alert_merge, favorite_city_duplicates;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_favorite_cities_user_location ON favorite_cities (user_id, ROUND(lat, 2), ROUND(lon, 2));
+
+-- A ordem inicial é a de criação
+UPDATE favorite_cities fc
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, id) - 1 AS position
+    FROM favorite_cities
+) ordered
+WHERE ordered.id = fc.id;
+
+CREATE INDEX IF NOT EXISTS idx_favorite_cities_user_position ON favorite_cities (user_id, position);
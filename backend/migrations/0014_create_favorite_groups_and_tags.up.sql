@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS favorite_groups (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_favorite_groups_user_name ON favorite_groups (user_id, LOWER(name));
+
+-- Apagar um grupo devolve as favoritas dele para fora de qualquer grupo
+ALTER TABLE favorite_cities
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES favorite_groups(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_favorite_cities_group ON favorite_cities (group_id) WHERE group_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_favorite_cities_tags ON favorite_cities USING GIN (tags);
@@ -0,0 +1,86 @@
+-- Cada alteração de favoritas ou configurações recebe a próxima versão do
+-- usuário. O contador fica na linha do usuário, travada pela transação que
+-- altera, então as versões seguem a ordem de commit e o cursor de
+-- sincronização nunca pula uma alteração ainda não confirmada.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE favorite_cities
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+ALTER TABLE notification_settings
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_favorite_cities_user_version ON favorite_cities (user_id, version);
+
+-- Registros apagados, para que os outros aparelhos removam a cópia local
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    entity VARCHAR(32) NOT NULL,
+    record_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    version BIGINT NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (entity, record_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_tombstones_user_version ON sync_tombstones (user_id, version);
+
+-- Numera a linha e, se a alteração não informou updated_at (alterações pela
+-- API comum), usa o horário atual; a sincronização grava o horário do
+-- aparelho. Tabelas com remoção passam a entidade como argumento, e recriar um
+-- registro apagado com o mesmo ID descarta o tombstone.
+CREATE OR REPLACE FUNCTION bump_sync_version() RETURNS trigger AS $$
+BEGIN
+    UPDATE users SET sync_version = sync_version + 1
+    WHERE id = NEW.user_id
+    RETURNING sync_version INTO NEW.version;
+
+    IF TG_OP = 'UPDATE' AND NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at := NOW();
+    ELSIF TG_OP = 'INSERT' AND TG_NARGS > 0 THEN
+        DELETE FROM sync_tombstones WHERE entity = TG_ARGV[0] AND record_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Registra a remoção. Quando o próprio usuário está sendo apagado não há mais
+-- quem sincronizar, e a linha dele já não existe.
+CREATE OR REPLACE FUNCTION record_sync_tombstone() RETURNS trigger AS $$
+DECLARE
+    next_version BIGINT;
+BEGIN
+    UPDATE users SET sync_version = sync_version + 1
+    WHERE id = OLD.user_id
+    RETURNING sync_version INTO next_version;
+
+    IF next_version IS NOT NULL THEN
+        INSERT INTO sync_tombstones (entity, record_id, user_id, version, deleted_at)
+        VALUES (TG_ARGV[0], OLD.id, OLD.user_id, next_version, NOW())
+        ON CONFLICT (entity, record_id) DO UPDATE SET version = EXCLUDED.version, deleted_at = EXCLUDED.deleted_at;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS favorite_cities_sync_version ON favorite_cities;
+CREATE TRIGGER favorite_cities_sync_version
+    BEFORE INSERT OR UPDATE ON favorite_cities
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_version('favorite_city');
+
+DROP TRIGGER IF EXISTS favorite_cities_sync_tombstone ON favorite_cities;
+CREATE TRIGGER favorite_cities_sync_tombstone
+    AFTER DELETE ON favorite_cities
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('favorite_city');
+
+-- last_digest_sent_on é controle interno e não conta como alteração
+DROP TRIGGER IF EXISTS notification_settings_sync_version ON notification_settings;
+CREATE TRIGGER notification_settings_sync_version
+    BEFORE INSERT OR UPDATE OF is_enabled, status_bar_notification, rain_alert, severe_weather_alert,
+        timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end, category_overrides,
+        digest_enabled, digest_time, updated_at
+    ON notification_settings
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_version();
@@ -0,0 +1,5 @@
+-- Instante em que uma réplica reservou a notificação para enviá-la. Enquanto a
+-- reserva vale, nem o envio imediato nem o despachante de outra réplica a
+-- enviam de novo; se a réplica cair, a reserva expira e o envio é retomado.
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;